@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Gaurav-Gosain/scraped/output"
+	"github.com/Gaurav-Gosain/scraped/scraper"
+	"github.com/Gaurav-Gosain/scraped/tui"
+	"github.com/spf13/cobra"
+)
+
+type checkConfig struct {
+	Depth        int
+	Parallelism  int
+	HostPar      int
+	Delay        time.Duration
+	MaxPages     int
+	External     bool
+	IgnoreRobots bool
+	Include      []string
+	Exclude      []string
+	PathPrefix   string
+	Retries      int
+	RetryBackoff time.Duration
+	Session      sessionConfig
+	Raw          bool
+}
+
+func newCheckCmd() *cobra.Command {
+	cfg := &checkConfig{}
+
+	cmd := &cobra.Command{
+		Use:   "check <url>",
+		Short: "Crawl a site and report broken links and anchors",
+		Long:  "Crawls a site, requests every link found on its pages and reports links whose target fails or lacks the #fragment they point to.\nExits with an error when anything is broken, so it can gate a release.",
+		Example: `  # Check a docs site before publishing it
+  scraped check https://docs.example.com
+
+  # Also verify links to other sites
+  scraped check --external -d 5 https://example.com`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			return runCheck(c.Context(), cfg, args[0])
+		},
+	}
+
+	cmd.Flags().IntVarP(&cfg.Depth, "depth", "d", 3, "Crawl depth below the starting page")
+	cmd.Flags().IntVarP(&cfg.Parallelism, "parallelism", "p", 10, "Number of parallel requests across all hosts")
+	cmd.Flags().IntVar(&cfg.HostPar, "host-parallelism", 8, "Number of parallel requests to any one host")
+	cmd.Flags().DurationVar(&cfg.Delay, "delay", 0, "Minimum time between requests to the same host")
+	cmd.Flags().IntVarP(&cfg.MaxPages, "max-pages", "m", 0, "Max pages to crawl (0 = unlimited)")
+	cmd.Flags().BoolVar(&cfg.External, "external", false, "Also check links to other sites")
+	cmd.Flags().BoolVar(&cfg.IgnoreRobots, "ignore-robots", false, "Ignore robots.txt rules and Crawl-delay")
+	cmd.Flags().StringArrayVar(&cfg.Include, "include", nil, "Only crawl links matching this regex (or glob:pattern); repeatable")
+	cmd.Flags().StringArrayVar(&cfg.Exclude, "exclude", nil, "Do not crawl links matching this regex (or glob:pattern); repeatable")
+	cmd.Flags().StringVar(&cfg.PathPrefix, "path-prefix", "", "Only crawl links whose path starts with this prefix")
+	cmd.Flags().IntVar(&cfg.Retries, "retries", 2, "Retries for transient failures (5xx, 429, timeouts)")
+	cmd.Flags().DurationVar(&cfg.RetryBackoff, "retry-backoff", time.Second, "Base delay for exponential retry backoff")
+	cfg.Session.addFlags(cmd)
+	cmd.Flags().BoolVarP(&cfg.Raw, "raw", "r", false, "Log progress instead of showing the progress TUI")
+
+	return cmd
+}
+
+func runCheck(ctx context.Context, cfg *checkConfig, rawURL string) error {
+	u, err := validateURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	opts := scraper.Options{
+		URLs:            []string{u},
+		Depth:           cfg.Depth,
+		Parallelism:     cfg.Parallelism,
+		HostParallelism: cfg.HostPar,
+		Delay:           cfg.Delay,
+		MaxPages:        cfg.MaxPages,
+		IgnoreRobots:    cfg.IgnoreRobots,
+		Include:         cfg.Include,
+		Exclude:         cfg.Exclude,
+		PathPrefix:      cfg.PathPrefix,
+		Retries:         cfg.Retries,
+		RetryBackoff:    cfg.RetryBackoff,
+		// Pages are only crawled for their links; nothing is kept.
+		OnResult: func(scraper.Result) {},
+	}
+	cfg.Session.apply(&opts)
+	checker := scraper.NewLinkChecker(cfg.External)
+	opts = checker.Instrument(opts)
+
+	if _, err := tui.RunWithProgress(ctx, opts, cfg.Raw || !stdoutIsTTY()); err != nil {
+		return fmt.Errorf("crawl failed: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, "Checking links...")
+	report, err := checker.Check(ctx)
+	if err != nil {
+		return fmt.Errorf("link check failed: %w", err)
+	}
+	if err := output.WriteLinkReport(os.Stdout, report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if n := len(report.Broken); n > 0 {
+		return fmt.Errorf("found %d broken links", n)
+	}
+	return nil
+}
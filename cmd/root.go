@@ -4,9 +4,12 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Gaurav-Gosain/scraped/output"
 	"github.com/Gaurav-Gosain/scraped/scraper"
@@ -16,11 +19,41 @@ import (
 
 type config struct {
 	OutputDir    string
+	Layout       string
+	Combine      string
+	LLMsTxt      string
 	Depth        int
 	Parallelism  int
+	HostPar      int
+	Delay        time.Duration
+	RandomDelay  time.Duration
 	WordWrap     int
 	MaxPages     int
 	CrossDomains bool
+	IgnoreRobots bool
+	Sitemap      bool
+	Include      []string
+	Exclude      []string
+	PathPrefix   string
+	Retries      int
+	RetryBackoff time.Duration
+	CacheDir     string
+	Offline      bool
+	StateFile    string
+	Resume       string
+	Readability  bool
+	KeepBoiler   bool
+	DiscoverMD   bool
+	Dedupe       string
+	Selector     string
+	ExcludeSel   string
+	Assets       bool
+	AssetDomains []string
+	MaxAssetSize int64
+	Session      sessionConfig
+	Format       string
+	ChunkSize    int
+	ChunkOverlap int
 	Raw          bool
 }
 
@@ -41,7 +74,62 @@ func NewRootCmd() *cobra.Command {
   cat urls.txt | scraped
 
   # Crawl with depth
-  scraped -d 2 -p 20 https://example.com`,
+  scraped -d 2 -p 20 https://example.com
+
+  # Crawl many sites at once while going easy on each of them
+  scraped -d 1 -p 32 --host-parallelism 2 --delay 500ms --random-delay 500ms < sites.txt
+
+  # Save a crawl as a directory tree mirroring the site
+  scraped -d 2 --layout tree -o ./docs https://example.com
+
+  # Bundle a whole docs site into one file
+  scraped -d 3 --combine docs.md https://docs.example.com
+
+  # Publish a crawled docs site for AI tools
+  scraped --sitemap --llms-txt ./public https://docs.example.com
+
+  # Scrape every page listed in the site's sitemaps
+  scraped --sitemap -o ./docs https://example.com
+
+  # Journal a large crawl, then continue it after an interruption
+  scraped -d 3 --state-file crawl.state -o ./docs https://example.com
+  scraped --resume crawl.state -o ./docs
+
+  # Save a page for offline reading, images included
+  scraped --download-assets -o ./archive https://example.com/blog/post
+
+  # Collapse pages served under several URLs or with near-identical content
+  scraped -d 2 --dedupe similar -o ./docs https://example.com
+
+  # Scrape internal docs behind a login, reusing the browser's session cookie
+  scraped -d 2 --cookie-jar cookies.txt --user-agent "docs-bot (ops@example.com)" https://docs.internal.example.com
+
+  # Send an API key to one host only
+  scraped --header "api.example.com=X-Api-Key: $KEY" https://api.example.com/docs
+
+  # Crawl an internal wiki with a bearer token from the environment
+  scraped -d 3 --auth "wiki.example.com=bearer:WIKI_TOKEN" https://wiki.example.com
+
+  # Spread a crawl of many sites across two egress proxies
+  scraped -d 1 --proxy socks5://10.0.0.1:1080 --proxy http://10.0.0.2:3128 < sites.txt
+
+  # Scrape a staging site signed by a private CA that requires a client certificate
+  scraped --ca-cert corp-ca.pem --client-cert me.pem --client-key me.key https://staging.internal.example.com
+
+  # Keep only the article body, dropping navigation and footers
+  scraped --readability https://example.com/blog/post
+
+  # Keep the navigation and footers repeated on every page of a crawl
+  scraped -d 2 --keep-boilerplate -o ./docs https://example.com
+
+  # Stream pages as JSON Lines for jq
+  scraped -d 1 --format jsonl https://example.com | jq -r .title
+
+  # Chunk a docs site for embedding, without the navigation repeated on every page
+  scraped -d 2 --chunk-size 512 --chunk-overlap 64 https://docs.example.com > chunks.jsonl
+
+  # Crawl only the guide, skipping localized pages
+  scraped -d 3 --path-prefix /guide/ --exclude 'glob:*/fr/*' https://docs.example.com/guide/`,
 		RunE: func(c *cobra.Command, args []string) error {
 			return run(c.Context(), cfg, args)
 		},
@@ -50,16 +138,96 @@ func NewRootCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&cfg.OutputDir, "output-dir", "o", "", "Save .md files to directory (default: render to terminal)")
+	cmd.Flags().StringVar(&cfg.Layout, "layout", output.LayoutFlat, "Output directory layout: flat (host-path.md) or tree (host/path/index.md)")
+	cmd.Flags().StringVar(&cfg.Combine, "combine", "", "Write all pages to this single .md file with a table of contents")
+	cmd.Flags().StringVar(&cfg.LLMsTxt, "llms-txt", "", "Write llms.txt and llms-full.txt for the scraped pages to this directory")
 	cmd.Flags().IntVarP(&cfg.Depth, "depth", "d", 0, "Crawl depth (0 = only given URLs)")
-	cmd.Flags().IntVarP(&cfg.Parallelism, "parallelism", "p", 10, "Number of parallel requests")
+	cmd.Flags().IntVarP(&cfg.Parallelism, "parallelism", "p", 10, "Number of parallel requests across all hosts")
+	cmd.Flags().IntVar(&cfg.HostPar, "host-parallelism", 8, "Number of parallel requests to any one host")
+	cmd.Flags().DurationVar(&cfg.Delay, "delay", 0, "Minimum time between requests to the same host")
+	cmd.Flags().DurationVar(&cfg.RandomDelay, "random-delay", 0, "Add up to this much random time to each per-host delay")
 	cmd.Flags().IntVarP(&cfg.WordWrap, "word-wrap", "w", 80, "Word wrap width for terminal rendering")
 	cmd.Flags().IntVarP(&cfg.MaxPages, "max-pages", "m", 0, "Max pages to scrape (0 = unlimited)")
 	cmd.Flags().BoolVar(&cfg.CrossDomains, "cross-domains", false, "Allow crawling across different domains")
+	cmd.Flags().BoolVar(&cfg.IgnoreRobots, "ignore-robots", false, "Ignore robots.txt rules and Crawl-delay when crawling")
+	cmd.Flags().BoolVar(&cfg.Sitemap, "sitemap", false, "Discover pages from robots.txt and /sitemap.xml sitemaps, or only from sitemap URLs given as arguments")
+	cmd.Flags().StringArrayVar(&cfg.Include, "include", nil, "Only follow links matching this regex (or glob:pattern); repeatable")
+	cmd.Flags().StringArrayVar(&cfg.Exclude, "exclude", nil, "Skip links matching this regex (or glob:pattern); repeatable")
+	cmd.Flags().StringVar(&cfg.PathPrefix, "path-prefix", "", "Only follow links whose path starts with this prefix")
+	cmd.Flags().IntVar(&cfg.Retries, "retries", 2, "Retries for transient failures (5xx, 429, timeouts)")
+	cmd.Flags().DurationVar(&cfg.RetryBackoff, "retry-backoff", time.Second, "Base delay for exponential retry backoff")
+	cmd.Flags().StringVar(&cfg.CacheDir, "cache-dir", "", "Cache responses in this directory and revalidate them on later runs")
+	cmd.Flags().BoolVar(&cfg.Offline, "offline", false, "Replay responses from --cache-dir without touching the network")
+	cmd.Flags().StringVar(&cfg.StateFile, "state-file", "", "Journal crawl progress to this file so it can be resumed")
+	cmd.Flags().StringVar(&cfg.Resume, "resume", "", "Resume the interrupted crawl recorded in this state file")
+	cmd.Flags().BoolVar(&cfg.DiscoverMD, "discover-md", false, "Prefer markdown the site publishes: /llms.txt links and page.md siblings")
+	cmd.Flags().StringVar(&cfg.Dedupe, "dedupe", "", "Also collapse duplicate pages by content: exact or similar (near-duplicates)")
+	cmd.Flags().BoolVar(&cfg.Readability, "readability", false, "Keep only the main article content of HTML pages")
+	cmd.Flags().BoolVar(&cfg.KeepBoiler, "keep-boilerplate", false, "Keep blocks repeated across a site's pages (navigation, footers) instead of removing them; JSON and chunk output then stream as pages finish")
+	cmd.Flags().StringVar(&cfg.Selector, "selector", "", "Convert only elements matching this CSS selector")
+	cmd.Flags().StringVar(&cfg.ExcludeSel, "exclude-selector", "", "Drop elements matching this CSS selector before conversion")
+	cmd.Flags().BoolVar(&cfg.Assets, "download-assets", false, "Download images into <output-dir>/assets and link them locally")
+	cmd.Flags().StringArrayVar(&cfg.AssetDomains, "asset-domain", nil, "Also download assets from this domain and its subdomains; repeatable")
+	cmd.Flags().Int64Var(&cfg.MaxAssetSize, "max-asset-size", 10<<20, "Skip assets larger than this many bytes")
+	cfg.Session.addFlags(cmd)
+	cmd.Flags().StringVarP(&cfg.Format, "format", "f", "markdown", "Output format: markdown, json or jsonl (JSON is streamed to stdout)")
+	cmd.Flags().IntVar(&cfg.ChunkSize, "chunk-size", 0, "Split pages into chunks of at most this many tokens, emitted as JSONL (0 = off)")
+	cmd.Flags().IntVar(&cfg.ChunkOverlap, "chunk-overlap", 0, "Tokens of context repeated between consecutive chunks, at most half of --chunk-size")
 	cmd.Flags().BoolVarP(&cfg.Raw, "raw", "r", false, "Output raw markdown without TUI or ANSI formatting")
 
+	cmd.MarkFlagsMutuallyExclusive("state-file", "resume")
+	cmd.MarkFlagsMutuallyExclusive("combine", "output-dir", "llms-txt")
+
+	cmd.AddCommand(newCheckCmd())
+
 	return cmd
 }
 
+// sessionConfig holds how requests are sent besides their URL: identity,
+// headers, cookies, credentials, proxies and TLS. The check command shares
+// it.
+type sessionConfig struct {
+	UserAgent  string
+	Headers    []string
+	Cookies    []string
+	CookieJar  string
+	Auth       []string
+	Netrc      bool
+	Proxies    []string
+	CACert     string
+	ClientCert string
+	ClientKey  string
+	Insecure   bool
+}
+
+func (s *sessionConfig) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.UserAgent, "user-agent", "", "Send this User-Agent instead of a random browser one; its product token also selects the robots.txt group")
+	cmd.Flags().StringArrayVarP(&s.Headers, "header", "H", nil, "Send header \"Name: value\" to the seed hosts, or to a domain with \"domain=Name: value\"; repeatable")
+	cmd.Flags().StringArrayVar(&s.Cookies, "cookie", nil, "Send cookie \"name=value\" to the seed hosts, or to a domain with \"name=value; Domain=example.com\"; repeatable")
+	cmd.Flags().StringVar(&s.CookieJar, "cookie-jar", "", "Load cookies from this Netscape cookies.txt file and save them back after the run")
+	cmd.Flags().StringArrayVar(&s.Auth, "auth", nil, "Credentials for one host: \"host=basic:user:password\" or \"host=bearer:ENV_VAR\"; repeatable")
+	cmd.Flags().BoolVar(&s.Netrc, "netrc", false, "Look up credentials for hosts in $NETRC or ~/.netrc")
+	cmd.Flags().StringArrayVar(&s.Proxies, "proxy", nil, "Send requests through this http(s):// or socks5:// proxy, or route one domain with \"domain=URL\"; repeat to rotate proxies across hosts")
+	cmd.Flags().StringVar(&s.CACert, "ca-cert", "", "Also trust the CA certificates in this PEM file")
+	cmd.Flags().StringVar(&s.ClientCert, "client-cert", "", "Present this PEM client certificate to servers that require mutual TLS")
+	cmd.Flags().StringVar(&s.ClientKey, "client-key", "", "PEM private key for --client-cert, if not in the same file")
+	cmd.Flags().BoolVar(&s.Insecure, "insecure", false, "Skip server certificate verification (unsafe)")
+}
+
+func (s *sessionConfig) apply(opts *scraper.Options) {
+	opts.UserAgent = s.UserAgent
+	opts.Headers = s.Headers
+	opts.Cookies = s.Cookies
+	opts.CookieJar = s.CookieJar
+	opts.Auth = s.Auth
+	opts.Netrc = s.Netrc
+	opts.Proxies = s.Proxies
+	opts.CACert = s.CACert
+	opts.ClientCert = s.ClientCert
+	opts.ClientKey = s.ClientKey
+	opts.Insecure = s.Insecure
+}
+
 // validateURL normalizes and validates a URL string.
 // If the input has no scheme but contains a ".", https:// is assumed.
 // Returns the normalized URL or an error if the URL is invalid.
@@ -89,27 +257,106 @@ func run(ctx context.Context, cfg *config, args []string) error {
 	if err != nil {
 		return err
 	}
-	if len(urls) == 0 {
+	if cfg.Resume != "" {
+		// The state file already records the seeds and crawl scope.
+		if len(urls) > 0 {
+			return fmt.Errorf("--resume continues the crawl recorded in %s; do not pass URLs", cfg.Resume)
+		}
+	} else if len(urls) == 0 {
 		return fmt.Errorf("no URLs provided; pass them as arguments or pipe via stdin")
 	}
+	switch cfg.Format {
+	case "markdown", "json", "jsonl":
+	default:
+		return fmt.Errorf("invalid --format %q: must be markdown, json or jsonl", cfg.Format)
+	}
+	switch cfg.Dedupe {
+	case "", scraper.DedupeExact, scraper.DedupeSimilar:
+	default:
+		return fmt.Errorf("invalid --dedupe %q: must be %s or %s", cfg.Dedupe, scraper.DedupeExact, scraper.DedupeSimilar)
+	}
+	if cfg.Layout != output.LayoutFlat && cfg.OutputDir == "" {
+		return fmt.Errorf("--layout %s requires --output-dir", cfg.Layout)
+	}
+	if cfg.ChunkSize > 0 && (cfg.OutputDir != "" || cfg.Combine != "" || cfg.LLMsTxt != "" || cfg.Format == "json") {
+		return fmt.Errorf("--chunk-size writes JSONL chunks to stdout and cannot be combined with other outputs")
+	}
+	if cfg.Assets && cfg.OutputDir == "" {
+		return fmt.Errorf("--download-assets requires --output-dir")
+	}
+	if cfg.Format != "markdown" && cfg.OutputDir != "" {
+		return fmt.Errorf("--format %s writes to stdout and cannot be combined with --output-dir", cfg.Format)
+	}
+	if cfg.Format != "markdown" && (cfg.Combine != "" || cfg.LLMsTxt != "") {
+		return fmt.Errorf("--format %s writes to stdout and cannot be combined with --combine or --llms-txt", cfg.Format)
+	}
 
 	opts := scraper.Options{
-		URLs:         urls,
-		Depth:        cfg.Depth,
-		Parallelism:  cfg.Parallelism,
-		MaxPages:     cfg.MaxPages,
-		CrossDomains: cfg.CrossDomains,
+		URLs:            urls,
+		Depth:           cfg.Depth,
+		Parallelism:     cfg.Parallelism,
+		HostParallelism: cfg.HostPar,
+		Delay:           cfg.Delay,
+		RandomDelay:     cfg.RandomDelay,
+		MaxPages:        cfg.MaxPages,
+		CrossDomains:    cfg.CrossDomains,
+		IgnoreRobots:    cfg.IgnoreRobots,
+		Sitemap:         cfg.Sitemap,
+		Include:         cfg.Include,
+		Exclude:         cfg.Exclude,
+		PathPrefix:      cfg.PathPrefix,
+		Retries:         cfg.Retries,
+		RetryBackoff:    cfg.RetryBackoff,
+		CacheDir:        cfg.CacheDir,
+		Offline:         cfg.Offline,
+		StateFile:       cfg.StateFile,
+		Readability:     cfg.Readability,
+		DiscoverMD:      cfg.DiscoverMD,
+		Dedupe:          cfg.Dedupe,
+		Selector:        cfg.Selector,
+		ExcludeSelector: cfg.ExcludeSel,
+		AssetDomains:    cfg.AssetDomains,
+		MaxAssetSize:    cfg.MaxAssetSize,
+	}
+	cfg.Session.apply(&opts)
+	if cfg.Assets {
+		opts.AssetDir = filepath.Join(cfg.OutputDir, output.AssetsDir)
+	}
+	if cfg.Resume != "" {
+		opts.StateFile = cfg.Resume
+		opts.Resume = true
+	}
+
+	// Removing boilerplate needs every page, so JSON and chunk output
+	// only stream as pages finish with --keep-boilerplate.
+	strip := !cfg.KeepBoiler
+
+	if cfg.ChunkSize > 0 {
+		return runChunks(ctx, opts, output.Chunker{Size: cfg.ChunkSize, Overlap: cfg.ChunkOverlap}, strip)
+	}
+	if cfg.Format != "markdown" {
+		return runJSON(ctx, opts, cfg.Format == "json", strip)
 	}
 
 	noTUI := cfg.Raw || !stdoutIsTTY()
 
+	if cfg.OutputDir != "" {
+		return runFiles(ctx, opts, cfg.OutputDir, cfg.Layout, noTUI, strip)
+	}
+
 	results, err := tui.RunWithProgress(ctx, opts, noTUI)
 	if err != nil {
 		return fmt.Errorf("scraping failed: %w", err)
 	}
+	if strip {
+		output.WriteBoilerplateReport(os.Stderr, output.StripBoilerplate(results))
+	}
 
-	if cfg.OutputDir != "" {
-		return output.WriteFiles(results, cfg.OutputDir)
+	if cfg.Combine != "" {
+		return output.WriteCombined(results, cfg.Combine)
+	}
+	if cfg.LLMsTxt != "" {
+		return output.WriteLLMsTxt(results, cfg.LLMsTxt)
 	}
 
 	// Raw mode or non-TTY stdout: output plain markdown without ANSI.
@@ -133,6 +380,109 @@ func run(ctx context.Context, cfg *config, args []string) error {
 	return output.RenderTerminal(results, cfg.WordWrap)
 }
 
+// runJSON streams each result to stdout as soon as it is scraped. Progress
+// goes to stderr as log lines since stdout carries the data. Removing
+// boilerplate needs every page first, so with strip results are written at
+// the end instead.
+func runJSON(ctx context.Context, opts scraper.Options, array, strip bool) error {
+	jw := output.NewJSONWriter(os.Stdout, array)
+	err := runStream(ctx, opts, strip, func(r scraper.Result) {
+		_ = jw.Write(r)
+	}, func(alias, keptURL string) {
+		_ = jw.Alias(alias, keptURL)
+	})
+	if cerr := jw.Close(); cerr != nil && err == nil {
+		return fmt.Errorf("failed to write output: %w", cerr)
+	}
+	if err != nil {
+		return fmt.Errorf("scraping failed: %w", err)
+	}
+	return nil
+}
+
+// runChunks streams each page to stdout as JSONL chunks for embedding,
+// after the crawl when boilerplate is removed.
+func runChunks(ctx context.Context, opts scraper.Options, chunker output.Chunker, strip bool) error {
+	cw, err := output.NewChunkWriter(os.Stdout, chunker)
+	if err != nil {
+		return err
+	}
+	err = runStream(ctx, opts, strip, func(r scraper.Result) {
+		_ = cw.Write(r)
+	}, func(alias, keptURL string) {
+		_ = cw.Alias(alias, keptURL)
+	})
+	if cerr := cw.Close(); cerr != nil && err == nil {
+		return fmt.Errorf("failed to write output: %w", cerr)
+	}
+	if err != nil {
+		return fmt.Errorf("scraping failed: %w", err)
+	}
+	return nil
+}
+
+// runStream scrapes with progress logged to stderr, passing each result to
+// write as it finishes. A duplicate found after its page was written is
+// passed to alias, since that page's aliases can no longer change. With
+// strip, results are held until the crawl ends so boilerplate can be
+// detected across all of them; they carry their aliases, and whatever was
+// collected is still written if the crawl fails.
+func runStream(ctx context.Context, opts scraper.Options, strip bool, write func(scraper.Result), alias func(alias, keptURL string)) error {
+	if !strip {
+		opts.OnResult = write
+		opts.OnEvent = func(e scraper.Event) {
+			if e.Type == "duplicate" {
+				alias(e.URL, e.Of)
+			}
+		}
+		_, err := tui.RunWithProgress(ctx, opts, true)
+		return err
+	}
+	results, err := tui.RunWithProgress(ctx, opts, true)
+	output.WriteBoilerplateReport(os.Stderr, output.StripBoilerplate(results))
+	for _, r := range results {
+		write(r)
+	}
+	return err
+}
+
+// runFiles saves each page to dir as soon as it is scraped rather than
+// holding the whole crawl in memory. Boilerplate is removed from the saved
+// files once the crawl ends.
+func runFiles(ctx context.Context, opts scraper.Options, dir, layout string, noTUI, strip bool) error {
+	// Per-file lines would corrupt the progress TUI; it gets a summary.
+	logs := noTUI || !tui.IsTTY()
+	var progress io.Writer
+	if logs {
+		progress = os.Stderr
+	}
+	fw, err := output.NewFileWriter(dir, layout, progress)
+	if err != nil {
+		return err
+	}
+	fw.RemoveBoilerplate = strip
+	opts.OnResult = fw.Write
+	opts.OnEvent = func(e scraper.Event) {
+		if e.Type == "duplicate" {
+			fw.Alias(e.URL, e.Of)
+		}
+	}
+
+	_, err = tui.RunWithProgress(ctx, opts, noTUI)
+	// Finish links and the manifest for whatever was saved, even on failure.
+	if cerr := fw.Close(); cerr != nil && err == nil {
+		return cerr
+	}
+	if err != nil {
+		return fmt.Errorf("scraping failed: %w", err)
+	}
+	if !logs {
+		fmt.Fprintf(os.Stderr, "Saved %d files to %s\n", fw.Saved(), dir)
+	}
+	output.WriteBoilerplateReport(os.Stderr, fw.Boilerplate())
+	return nil
+}
+
 func stdoutIsTTY() bool {
 	fi, err := os.Stdout.Stat()
 	if err != nil {
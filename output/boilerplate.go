@@ -0,0 +1,282 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+const (
+	// boilerplateMinPages is how many pages a host needs before its
+	// repeated blocks are judged; smaller crawls are left alone.
+	boilerplateMinPages = 4
+	// boilerplateShare is the fraction of a host's pages a block must
+	// appear on, verbatim, to count as boilerplate.
+	boilerplateShare = 0.5
+	// maxBoilerplateShown caps how many blocks the report lists.
+	maxBoilerplateShown = 10
+)
+
+// BoilerplateBlock is a block removed from a host's pages because it
+// repeated across them: navigation, cookie banners, footers and the like.
+type BoilerplateBlock struct {
+	Host  string
+	Text  string
+	Pages int // pages it was removed from
+}
+
+// boilerplate finds blocks repeated across the pages of each host. Pages
+// are first counted with add, then cleaned with strip, so callers can
+// stream pages from disk twice instead of holding them all in memory.
+type boilerplate struct {
+	pages   map[string]int                          // host → pages added
+	counts  map[string]map[uint64]int               // host → block hash → pages containing it
+	removed map[string]map[uint64]*BoilerplateBlock // host → block hash → report entry
+}
+
+func newBoilerplate() *boilerplate {
+	return &boilerplate{
+		pages:   make(map[string]int),
+		counts:  make(map[string]map[uint64]int),
+		removed: make(map[string]map[uint64]*BoilerplateBlock),
+	}
+}
+
+// add counts the blocks of one page of host.
+func (b *boilerplate) add(host, md string) {
+	b.pages[host]++
+	counts := b.counts[host]
+	if counts == nil {
+		counts = make(map[uint64]int)
+		b.counts[host] = counts
+	}
+	seen := make(map[uint64]bool)
+	for _, seg := range splitSegments(md) {
+		if !seg.candidate {
+			continue
+		}
+		h := blockHash(seg.body)
+		if !seen[h] {
+			seen[h] = true
+			counts[h]++
+		}
+	}
+}
+
+// threshold returns how many of host's pages a block must appear on to be
+// removed, or 0 when host has too few pages to tell.
+func (b *boilerplate) threshold(host string) int {
+	n := b.pages[host]
+	if n < boilerplateMinPages {
+		return 0
+	}
+	return max(2, int(float64(n)*boilerplateShare+0.999))
+}
+
+// strip returns md without the blocks that repeat across host's pages.
+func (b *boilerplate) strip(host, md string) string {
+	limit := b.threshold(host)
+	if limit == 0 {
+		return md
+	}
+	counts := b.counts[host]
+	segs := splitSegments(md)
+	var out strings.Builder
+	changed := false
+	for _, seg := range segs {
+		if seg.candidate {
+			h := blockHash(seg.body)
+			if counts[h] >= limit {
+				b.record(host, h, seg.body)
+				changed = true
+				continue
+			}
+		}
+		out.WriteString(seg.raw)
+	}
+	if !changed {
+		return md
+	}
+	// A removed footer would leave the blank lines before it dangling.
+	return strings.TrimRight(out.String(), "\n") + "\n"
+}
+
+func (b *boilerplate) record(host string, h uint64, text string) {
+	removed := b.removed[host]
+	if removed == nil {
+		removed = make(map[uint64]*BoilerplateBlock)
+		b.removed[host] = removed
+	}
+	if e, ok := removed[h]; ok {
+		e.Pages++
+		return
+	}
+	removed[h] = &BoilerplateBlock{Host: host, Text: text, Pages: 1}
+}
+
+// report lists the removed blocks by host, most widespread first.
+func (b *boilerplate) report() []BoilerplateBlock {
+	var out []BoilerplateBlock
+	for _, removed := range b.removed {
+		for _, e := range removed {
+			out = append(out, *e)
+		}
+	}
+	slices.SortFunc(out, func(x, y BoilerplateBlock) int {
+		return cmp.Or(
+			strings.Compare(x.Host, y.Host),
+			cmp.Compare(y.Pages, x.Pages),
+			strings.Compare(x.Text, y.Text),
+		)
+	})
+	return out
+}
+
+// StripBoilerplate removes blocks that appear verbatim on a large share of
+// a host's pages from each result's markdown, in place, and returns what
+// was removed. Only paragraphs and lists are considered; headings, code
+// blocks and tables always stay.
+func StripBoilerplate(results []scraper.Result) []BoilerplateBlock {
+	b := newBoilerplate()
+	for _, r := range results {
+		if r.Err == nil {
+			b.add(resultHost(r), r.Markdown)
+		}
+	}
+	for i, r := range results {
+		if r.Err == nil {
+			results[i].Markdown = b.strip(resultHost(r), r.Markdown)
+		}
+	}
+	return b.report()
+}
+
+// WriteBoilerplateReport summarizes removed blocks, one line each.
+func WriteBoilerplateReport(w io.Writer, blocks []BoilerplateBlock) {
+	if len(blocks) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Removed %d boilerplate blocks repeated across pages (keep them with --keep-boilerplate):\n", len(blocks))
+	for _, bl := range blocks[:min(len(blocks), maxBoilerplateShown)] {
+		fmt.Fprintf(w, "  %s, %d pages: %s\n", bl.Host, bl.Pages, clip(oneLine(bl.Text), 60))
+	}
+	if len(blocks) > maxBoilerplateShown {
+		fmt.Fprintf(w, "  ...and %d more\n", len(blocks)-maxBoilerplateShown)
+	}
+}
+
+// clip shortens s to at most n runes, marking the cut with an ellipsis.
+func clip(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func resultHost(r scraper.Result) string {
+	return pageHost(cmp.Or(r.Meta.FinalURL, r.URL))
+}
+
+func pageHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+func blockHash(text string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return h.Sum64()
+}
+
+// mdSegment is a run of markdown between blank lines.
+type mdSegment struct {
+	raw       string // as in the page, including the blank lines after it
+	body      string // the lines themselves, trailing spaces trimmed
+	candidate bool   // paragraph or list, so it may be boilerplate
+}
+
+// splitSegments cuts md at blank lines outside fenced code. Joining the
+// raw text of every segment gives md back unchanged.
+func splitSegments(md string) []mdSegment {
+	var (
+		segs   []mdSegment
+		lines  []string
+		raw    strings.Builder
+		inCode bool
+		fences fenceState
+	)
+	flush := func() {
+		if raw.Len() == 0 {
+			return
+		}
+		seg := mdSegment{raw: raw.String(), body: strings.Join(lines, "\n")}
+		seg.candidate = !inCode && isProseBlock(lines)
+		segs = append(segs, seg)
+		raw.Reset()
+		lines = nil
+	}
+
+	for line := range strings.Lines(md) {
+		body := strings.TrimRight(line, " \t\r\n")
+		if fences.code(line) {
+			if !inCode {
+				flush()
+				inCode = true
+			}
+			raw.WriteString(line)
+			lines = append(lines, body)
+			continue
+		}
+		if inCode {
+			// The fence closed on the previous line.
+			flush()
+			inCode = false
+		}
+		if body == "" {
+			raw.WriteString(line)
+			if len(lines) > 0 {
+				flush()
+			}
+			continue
+		}
+		if len(lines) == 0 && raw.Len() > 0 {
+			// Leading blank lines before the first block.
+			flush()
+		}
+		raw.WriteString(line)
+		lines = append(lines, body)
+	}
+	flush()
+	return segs
+}
+
+// isProseBlock reports whether lines form a paragraph or list rather than
+// a heading, table, thematic break or indented code.
+func isProseBlock(lines []string) bool {
+	if len(lines) == 0 || isTable(lines) {
+		return false
+	}
+	for i, line := range lines {
+		if atxHeadingRe.MatchString(line) {
+			return false
+		}
+		if i > 0 && setextRe.MatchString(line) {
+			return false
+		}
+	}
+	first := lines[0]
+	if strings.HasPrefix(first, "    ") || strings.HasPrefix(first, "\t") {
+		return false
+	}
+	return strings.Trim(first, "-*_ ") != ""
+}
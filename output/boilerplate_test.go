@@ -0,0 +1,74 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+func TestStripBoilerplate(t *testing.T) {
+	const (
+		nav    = "- [Home](/)\n- [Docs](/docs)\n- [Blog](/blog)"
+		footer = "Copyright 2026 Example Inc. All rights reserved."
+	)
+	var results []scraper.Result
+	for i := range 5 {
+		md := fmt.Sprintf("%s\n\n# Page %d\n\nUnique text for page %d.\n\n%s\n", nav, i, i, footer)
+		results = append(results, scraper.Result{URL: fmt.Sprintf("https://example.com/p%d", i), Markdown: md})
+	}
+	// A page on another host keeps its copy: hosts are judged separately.
+	other := scraper.Result{URL: "https://other.example/", Markdown: footer + "\n"}
+	results = append(results, other)
+
+	blocks := StripBoilerplate(results)
+	if len(blocks) != 2 {
+		t.Fatalf("removed %d blocks, want 2: %+v", len(blocks), blocks)
+	}
+	for _, bl := range blocks {
+		if bl.Host != "example.com" || bl.Pages != 5 {
+			t.Errorf("block %q: host %q, %d pages; want example.com, 5", bl.Text, bl.Host, bl.Pages)
+		}
+	}
+	for i, r := range results[:5] {
+		want := fmt.Sprintf("# Page %d\n\nUnique text for page %d.\n", i, i)
+		if r.Markdown != want {
+			t.Errorf("page %d = %q, want %q", i, r.Markdown, want)
+		}
+	}
+	if results[5].Markdown != other.Markdown {
+		t.Errorf("other host's page changed to %q", results[5].Markdown)
+	}
+}
+
+func TestStripBoilerplateKeepsSmallCrawls(t *testing.T) {
+	var results []scraper.Result
+	for i := range boilerplateMinPages - 1 {
+		results = append(results, scraper.Result{URL: fmt.Sprintf("https://example.com/%d", i), Markdown: "Same footer everywhere.\n"})
+	}
+	if blocks := StripBoilerplate(results); len(blocks) != 0 {
+		t.Errorf("removed %d blocks from a %d-page crawl", len(blocks), len(results))
+	}
+}
+
+func TestSplitSegments(t *testing.T) {
+	md := "# Title\n\nA paragraph.\n\n```\ncode\n\nmore code\n```\n\n| a | b |\n|---|---|\n\n---\n\n- item\n"
+	var (
+		joined     strings.Builder
+		candidates []string
+	)
+	for _, seg := range splitSegments(md) {
+		joined.WriteString(seg.raw)
+		if seg.candidate {
+			candidates = append(candidates, seg.body)
+		}
+	}
+	if joined.String() != md {
+		t.Errorf("segments join to %q, want %q", joined.String(), md)
+	}
+	want := []string{"A paragraph.", "- item"}
+	if strings.Join(candidates, "|") != strings.Join(want, "|") {
+		t.Errorf("candidates = %q, want %q", candidates, want)
+	}
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+// WriteLinkReport prints a link check summary followed by each broken
+// target, why it is broken and every page linking to it.
+func WriteLinkReport(w io.Writer, report *scraper.LinkReport) error {
+	fmt.Fprintf(w, "Checked %d links on %d pages", report.Links, report.Pages)
+	if report.Skipped > 0 {
+		fmt.Fprintf(w, " (%d skipped)", report.Skipped)
+	}
+	_, err := fmt.Fprintf(w, ": %d broken\n", len(report.Broken))
+
+	last := ""
+	for _, b := range report.Broken {
+		if b.URL != last {
+			last = b.URL
+			fmt.Fprintf(w, "\n%s\n  %s\n", b.URL, brokenReason(b))
+		}
+		text := ""
+		if b.Text != "" {
+			text = " " + strconv.Quote(b.Text)
+		}
+		_, err = fmt.Fprintf(w, "  on %s%s\n", b.Source, text)
+	}
+	return err
+}
+
+func brokenReason(b scraper.BrokenLink) string {
+	switch {
+	case b.Fragment != "":
+		return "missing anchor #" + b.Fragment
+	case b.Status != 0:
+		return fmt.Sprintf("%d %s", b.Status, http.StatusText(b.Status))
+	case b.Err != nil:
+		return b.Err.Error()
+	}
+	return "broken"
+}
@@ -0,0 +1,376 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+// Tokenizer counts tokens the way the target embedding model would.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(text string) int
+
+func (f TokenizerFunc) CountTokens(text string) int { return f(text) }
+
+// ApproxTokenizer estimates tokens without a vocabulary: about four
+// characters per token for English prose, and never fewer than the number
+// of words. It is close enough for sizing chunks.
+type ApproxTokenizer struct{}
+
+func (ApproxTokenizer) CountTokens(text string) int {
+	chars := int(math.Ceil(float64(utf8.RuneCountInString(text)) / 4))
+	return max(chars, len(strings.Fields(text)))
+}
+
+// Chunk is one piece of a page, sized for embedding.
+type Chunk struct {
+	URL        string   `json:"url"`
+	Title      string   `json:"title,omitempty"`
+	Index      int      `json:"chunk_index"`
+	Breadcrumb []string `json:"breadcrumb,omitempty"` // headings enclosing the chunk, outermost first
+	Tokens     int      `json:"tokens"`
+	Text       string   `json:"text"`
+}
+
+// Chunker splits markdown along heading and paragraph boundaries into
+// chunks of at most Size tokens. Code blocks and tables are never split; one
+// that alone exceeds Size becomes its own oversized chunk. Consecutive
+// chunks of a section share up to Overlap tokens of context, capped at half
+// of Size.
+type Chunker struct {
+	Size      int
+	Overlap   int
+	Tokenizer Tokenizer // nil = ApproxTokenizer
+}
+
+// blockKind tells the chunker which blocks may be split or repeated.
+type blockKind int
+
+const (
+	blockText    blockKind = iota // paragraph, list, quote: split at words if needed
+	blockHeading                  // starts a section
+	blockAtomic                   // code block or table: never split
+)
+
+type mdBlock struct {
+	kind       blockKind
+	cont       bool // continues the previous block's paragraph after a split
+	text       string
+	breadcrumb []string
+	tokens     int
+}
+
+// Split chunks r's markdown. Failed results yield no chunks.
+func (c Chunker) Split(r scraper.Result) []Chunk {
+	if r.Err != nil || c.Size <= 0 {
+		return nil
+	}
+	tok := c.Tokenizer
+	if tok == nil {
+		tok = ApproxTokenizer{}
+	}
+	overlap := min(max(c.Overlap, 0), c.Size/2)
+
+	var (
+		chunks []Chunk
+		cur    []mdBlock
+		size   int
+	)
+	push := func(b mdBlock) {
+		cur = append(cur, b)
+		size += b.tokens
+	}
+	hasContent := func() bool {
+		return slices.ContainsFunc(cur, func(b mdBlock) bool { return b.kind != blockHeading })
+	}
+	// flush emits the current chunk. Trailing headings move on to open the
+	// next chunk; otherwise, with carry, the next chunk starts with the
+	// tail of this one as overlap.
+	flush := func(carry bool) {
+		end := len(cur)
+		for end > 0 && cur[end-1].kind == blockHeading {
+			end--
+		}
+		if end == 0 {
+			return
+		}
+		next := slices.Clone(cur[end:])
+		if len(next) == 0 && carry {
+			next = tail(cur[:end], overlap, tok)
+		}
+
+		var sb strings.Builder
+		for i, b := range cur[:end] {
+			if i > 0 {
+				if b.cont {
+					sb.WriteString(" ")
+				} else {
+					sb.WriteString("\n\n")
+				}
+			}
+			sb.WriteString(b.text)
+		}
+		text := sb.String()
+		chunks = append(chunks, Chunk{
+			URL:        r.URL,
+			Title:      r.Meta.Title,
+			Index:      len(chunks),
+			Breadcrumb: cur[0].breadcrumb,
+			Tokens:     tok.CountTokens(text),
+			Text:       text,
+		})
+
+		cur, size = nil, 0
+		for _, b := range next {
+			push(b)
+		}
+	}
+
+	for _, b := range parseBlocks(r.Markdown, tok) {
+		// New sections start a new chunk unless the current one is still
+		// small, so short sections are merged rather than left as slivers.
+		if b.kind == blockHeading && size > c.Size/4 {
+			flush(false)
+		}
+		if size+b.tokens <= c.Size {
+			push(b)
+			continue
+		}
+
+		if b.kind != blockText {
+			// Headings, code and tables are never split. An oversized code
+			// block or table becomes a chunk of its own.
+			if hasContent() {
+				flush(false)
+			}
+			push(b)
+			continue
+		}
+
+		words := strings.Fields(b.text)
+		first := true
+		carried := false // cur holds only the overlap of the previous chunk
+		for len(words) > 0 {
+			n := fitWords(words, c.Size-size, tok)
+			if n == 0 {
+				switch {
+				case carried:
+					// Not even one word fits beside the overlap, so drop
+					// it rather than emit a chunk that repeats it alone.
+					cur, size, carried = nil, 0, false
+					continue
+				case hasContent():
+					flush(true)
+					carried = hasContent()
+					continue
+				}
+				n = 1
+			}
+			text := strings.Join(words[:n], " ")
+			push(mdBlock{kind: blockText, cont: !first, text: text, breadcrumb: b.breadcrumb, tokens: tok.CountTokens(text)})
+			words, first, carried = words[n:], false, false
+		}
+	}
+	flush(false)
+	return chunks
+}
+
+// fitWords returns how many leading words fit in budget tokens.
+func fitWords(words []string, budget int, tok Tokenizer) int {
+	if budget <= 0 {
+		return 0
+	}
+	lo, hi := 0, len(words)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if tok.CountTokens(strings.Join(words[:mid], " ")) <= budget {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+// tail returns the trailing text of blocks worth at most n tokens, for
+// overlap. Whole blocks are preferred; a text block is cut at a word
+// boundary. Headings and atomic blocks end the search.
+func tail(blocks []mdBlock, n int, tok Tokenizer) []mdBlock {
+	var out []mdBlock
+	left := n
+	for i := len(blocks) - 1; i >= 0 && left > 0; i-- {
+		b := blocks[i]
+		if b.kind != blockText {
+			break
+		}
+		if b.tokens <= left {
+			out = append([]mdBlock{b}, out...)
+			left -= b.tokens
+			continue
+		}
+		words := strings.Fields(b.text)
+		keep := len(words)
+		for keep > 0 && tok.CountTokens(strings.Join(words[len(words)-keep:], " ")) > left {
+			keep--
+		}
+		if keep > 0 {
+			text := strings.Join(words[len(words)-keep:], " ")
+			out = append([]mdBlock{{kind: blockText, cont: b.cont, text: text, breadcrumb: b.breadcrumb, tokens: tok.CountTokens(text)}}, out...)
+		}
+		break
+	}
+	return out
+}
+
+// parseBlocks splits markdown into headings, atomic blocks (fenced code and
+// tables) and text blocks separated by blank lines, tracking the heading
+// breadcrumb in force at each block.
+func parseBlocks(md string, tok Tokenizer) []mdBlock {
+	var (
+		blocks []mdBlock
+		crumbs []string
+		levels []int
+		para   []string
+		code   []string
+		fences fenceState
+	)
+	add := func(kind blockKind, text string) {
+		text = strings.TrimRight(text, "\n ")
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		blocks = append(blocks, mdBlock{
+			kind:       kind,
+			text:       text,
+			breadcrumb: append([]string(nil), crumbs...),
+			tokens:     tok.CountTokens(text),
+		})
+	}
+	heading := func(level int, title string) {
+		for len(levels) > 0 && levels[len(levels)-1] >= level {
+			levels = levels[:len(levels)-1]
+			crumbs = crumbs[:len(crumbs)-1]
+		}
+		levels = append(levels, level)
+		crumbs = append(crumbs, title)
+	}
+	flushPara := func() {
+		if len(para) == 0 {
+			return
+		}
+		kind := blockText
+		if isTable(para) {
+			kind = blockAtomic
+		}
+		// A setext underline turns the paragraph's last line into a heading.
+		if n := len(para); n >= 2 {
+			if m := setextRe.FindStringSubmatch(para[n-1]); m != nil && isParagraphLine(para[n-2]) {
+				add(kind, strings.Join(para[:n-2], "\n"))
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				title := strings.TrimSpace(para[n-2])
+				heading(level, title)
+				add(blockHeading, strings.Repeat("#", level)+" "+title)
+				para = nil
+				return
+			}
+		}
+		add(kind, strings.Join(para, "\n"))
+		para = nil
+	}
+
+	for line := range strings.Lines(md) {
+		if fences.code(line) {
+			if len(code) == 0 {
+				flushPara()
+			}
+			code = append(code, strings.TrimRight(line, "\n"))
+			if fences.fence == "" {
+				add(blockAtomic, strings.Join(code, "\n"))
+				code = nil
+			}
+			continue
+		}
+		body := strings.TrimRight(line, "\n")
+		if strings.TrimSpace(body) == "" {
+			flushPara()
+			continue
+		}
+		if m := atxHeadingRe.FindStringSubmatch(body); m != nil {
+			flushPara()
+			title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(body[len(m[0]):]), "#"))
+			heading(len(m[2]), title)
+			add(blockHeading, body)
+			continue
+		}
+		para = append(para, body)
+	}
+	flushPara()
+	if len(code) > 0 {
+		// Unterminated fence: keep what there is as one block.
+		add(blockAtomic, strings.Join(code, "\n"))
+	}
+	return blocks
+}
+
+// isTable reports whether a paragraph is a pipe table.
+func isTable(lines []string) bool {
+	if len(lines) < 2 {
+		return false
+	}
+	for _, l := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(l), "|") {
+			return false
+		}
+	}
+	return true
+}
+
+// ChunkWriter streams chunks of each result as JSON Lines. It is safe for
+// concurrent use.
+type ChunkWriter struct {
+	chunker Chunker
+	jw      *JSONWriter
+}
+
+func NewChunkWriter(w io.Writer, c Chunker) (*ChunkWriter, error) {
+	if c.Size <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive, got %d", c.Size)
+	}
+	if c.Overlap < 0 || c.Overlap > c.Size/2 {
+		return nil, fmt.Errorf("chunk overlap must be between 0 and half the chunk size (%d), got %d", c.Size/2, c.Overlap)
+	}
+	return &ChunkWriter{chunker: c, jw: NewJSONWriter(w, false)}, nil
+}
+
+// Write chunks r and writes one JSON object per chunk.
+func (cw *ChunkWriter) Write(r scraper.Result) error {
+	for _, c := range cw.chunker.Split(r) {
+		if err := cw.jw.writeValue(c, r.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Alias writes a record marking alias as a duplicate of the page chunked
+// for keptURL, in the same form as JSONWriter.Alias.
+func (cw *ChunkWriter) Alias(alias, keptURL string) error {
+	return cw.jw.Alias(alias, keptURL)
+}
+
+// Close reports the first write error, if any.
+func (cw *ChunkWriter) Close() error {
+	return cw.jw.Close()
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+// wordTokenizer counts one token per word, which keeps sizes in the tests
+// easy to reason about.
+var wordTokenizer = TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+
+func TestChunkerSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		chunker Chunker
+		md      string
+		want    []string
+	}{
+		{
+			name:    "fits in one chunk",
+			chunker: Chunker{Size: 10, Tokenizer: wordTokenizer},
+			md:      "one two three\n\nfour five",
+			want:    []string{"one two three\n\nfour five"},
+		},
+		{
+			name:    "splits a paragraph at words",
+			chunker: Chunker{Size: 3, Tokenizer: wordTokenizer},
+			md:      "a b c d e f g",
+			want:    []string{"a b c", "d e f", "g"},
+		},
+		{
+			name:    "carries overlap into the next chunk",
+			chunker: Chunker{Size: 4, Overlap: 1, Tokenizer: wordTokenizer},
+			md:      "a b c d e f g",
+			want:    []string{"a b c d", "d e f g"},
+		},
+		{
+			name:    "keeps code blocks whole",
+			chunker: Chunker{Size: 3, Tokenizer: wordTokenizer},
+			md:      "intro\n\n```\nx y z w v\n```",
+			want:    []string{"intro", "```\nx y z w v\n```"},
+		},
+		{
+			name:    "starts a new chunk at a heading",
+			chunker: Chunker{Size: 6, Tokenizer: wordTokenizer},
+			md:      "# A\n\none two three\n\n# B\n\nfour five",
+			want:    []string{"# A\n\none two three", "# B\n\nfour five"},
+		},
+		{
+			// Regression: a word too big to fit beside the carried overlap
+			// used to flush the overlap forever.
+			name:    "word larger than size minus overlap",
+			chunker: Chunker{Size: 10, Overlap: 5},
+			md:      "short words here " + strings.Repeat("x", 40),
+			want:    []string{"short words here", strings.Repeat("x", 40)},
+		},
+		{
+			name:    "single oversized word",
+			chunker: Chunker{Size: 10, Overlap: 5},
+			md:      strings.Repeat("x", 40),
+			want:    []string{strings.Repeat("x", 40)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := tt.chunker.Split(scraper.Result{URL: "https://example.com/", Markdown: tt.md})
+			var got []string
+			for i, c := range chunks {
+				if c.Index != i {
+					t.Errorf("chunk %d has index %d", i, c.Index)
+				}
+				got = append(got, c.Text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkerBreadcrumb(t *testing.T) {
+	md := "# Guide\n\nintro text\n\n## Install\n\nrun the installer now please"
+	chunks := Chunker{Size: 4, Tokenizer: wordTokenizer}.Split(scraper.Result{Markdown: md})
+	if len(chunks) == 0 {
+		t.Fatal("Split() returned no chunks")
+	}
+	last := chunks[len(chunks)-1]
+	if got := strings.Join(last.Breadcrumb, " > "); got != "Guide > Install" {
+		t.Errorf("last chunk breadcrumb = %q, want %q", got, "Guide > Install")
+	}
+}
+
+func TestNewChunkWriter(t *testing.T) {
+	tests := []struct {
+		size, overlap int
+		wantErr       bool
+	}{
+		{size: 512, overlap: 0},
+		{size: 512, overlap: 256},
+		{size: 512, overlap: 257, wantErr: true},
+		{size: 512, overlap: -1, wantErr: true},
+		{size: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := NewChunkWriter(io.Discard, Chunker{Size: tt.size, Overlap: tt.overlap})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewChunkWriter(size %d, overlap %d) error = %v, want error %v", tt.size, tt.overlap, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+var (
+	atxHeadingRe = regexp.MustCompile(`^( {0,3})(#{1,6})([ \t]|$)`)
+	// setextRe matches the underline of a setext heading.
+	setextRe  = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	slugStrip = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// WriteCombined writes all successful results to a single markdown file at
+// path: a title, a table of contents, then one section per page in URL
+// tree order. Each page's headings are demoted one level below its section
+// anchor, and links between included pages jump to that anchor.
+func WriteCombined(results []scraper.Result, path string) error {
+	var pages []scraper.Result
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "Error scraping %s: %v\n", r.URL, r.Err)
+			continue
+		}
+		pages = append(pages, r)
+	}
+	slices.SortStableFunc(pages, func(a, b scraper.Result) int {
+		return compareURLTree(a.URL, b.URL)
+	})
+
+	anchors := make(map[string]string, len(pages)*2)
+	ids := make([]string, len(pages))
+	used := make(map[string]bool)
+	for i, r := range pages {
+		id := uniqueSlug("page-"+slug(urlLabel(r.URL)), used)
+		ids[i] = id
+		anchors[scraper.CanonicalURL(r.URL)] = id
+	}
+	for i, r := range pages {
+		for _, u := range append([]string{r.Meta.FinalURL}, r.Aliases...) {
+			if u == "" {
+				continue
+			}
+			if _, ok := anchors[scraper.CanonicalURL(u)]; !ok {
+				anchors[scraper.CanonicalURL(u)] = ids[i]
+			}
+		}
+	}
+
+	var b strings.Builder
+	title := "Combined documentation"
+	if len(pages) > 0 {
+		title = pageLabel(pages[0])
+	}
+	fmt.Fprintf(&b, "# %s\n\n## Contents\n\n", title)
+
+	minDepth := -1
+	for _, r := range pages {
+		if d := urlDepth(r.URL); minDepth < 0 || d < minDepth {
+			minDepth = d
+		}
+	}
+	for i, r := range pages {
+		indent := strings.Repeat("  ", min(urlDepth(r.URL)-minDepth, 5))
+		fmt.Fprintf(&b, "%s- [%s](#%s)\n", indent, escapeLinkText(pageLabel(r)), ids[i])
+	}
+
+	for i, r := range pages {
+		b.WriteString("\n---\n\n")
+		fmt.Fprintf(&b, "<a id=\"%s\"></a>\n\n", ids[i])
+
+		// The page's own h1 becomes the section heading when it has one.
+		md := combineLinks(strings.TrimSpace(r.Markdown), r, anchors)
+		heading := "# " + pageLabel(r)
+		if first, rest, _ := strings.Cut(md, "\n"); isH1(first) {
+			heading, md = first, strings.TrimLeft(rest, "\n")
+		}
+		b.WriteString(demoteHeadings(heading))
+		fmt.Fprintf(&b, "\n\n> Source: <%s>\n\n", r.URL)
+		b.WriteString(demoteHeadings(md))
+		b.WriteString("\n")
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write combined output: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Saved: %s (%d pages)\n", path, len(pages))
+	return nil
+}
+
+// combineLinks points links to other included pages at their section
+// anchor. Fragments are dropped: heading IDs within the combined file are
+// up to the renderer, so only the page anchor is reliable.
+func combineLinks(md string, r scraper.Result, anchors map[string]string) string {
+	base, err := url.Parse(cmp.Or(r.Meta.FinalURL, r.URL))
+	if err != nil {
+		return md
+	}
+	return rewriteLinks(md, func(dest string) (string, bool) {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return "", false
+		}
+		id, ok := anchors[scraper.CanonicalURL(base.ResolveReference(u).String())]
+		if !ok {
+			return "", false
+		}
+		return "#" + id, true
+	})
+}
+
+// demoteHeadings moves every heading in md one level down, so a page's
+// h1 sits under the combined document's title. h6 stays h6. Setext
+// headings are rewritten as ATX headings.
+func demoteHeadings(md string) string {
+	lines := strings.SplitAfter(md, "\n")
+	var fences fenceState
+	for i, line := range lines {
+		if fences.code(line) {
+			continue
+		}
+		body := strings.TrimRight(line, "\n")
+		nl := line[len(body):]
+
+		if m := atxHeadingRe.FindStringSubmatch(body); m != nil {
+			if len(m[2]) < 6 {
+				lines[i] = m[1] + "#" + body[len(m[1]):] + nl
+			}
+			continue
+		}
+		if m := setextRe.FindStringSubmatch(body); m != nil && i > 0 && isParagraphLine(lines[i-1]) {
+			level := "##"
+			if m[1][0] == '-' {
+				level = "###"
+			}
+			lines[i-1] = level + " " + strings.TrimSpace(lines[i-1]) + "\n"
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// isParagraphLine reports whether a setext underline after line would make
+// it a heading rather than, say, follow a blank line as a thematic break.
+func isParagraphLine(line string) bool {
+	t := strings.TrimSpace(line)
+	if t == "" {
+		return false
+	}
+	switch t[0] {
+	case '#', '>', '-', '*', '+', '|', '<':
+		return false
+	}
+	return true
+}
+
+func isH1(line string) bool {
+	m := atxHeadingRe.FindStringSubmatch(line)
+	return m != nil && m[2] == "#"
+}
+
+// pageLabel is how a page is named in the contents: its title, its first
+// h1, or its URL.
+func pageLabel(r scraper.Result) string {
+	if r.Meta.Title != "" {
+		return r.Meta.Title
+	}
+	var fences fenceState
+	for line := range strings.Lines(r.Markdown) {
+		if !fences.code(line) && isH1(line) {
+			if t := strings.Trim(strings.TrimSpace(line), "# "); t != "" {
+				return t
+			}
+		}
+	}
+	return urlLabel(r.URL)
+}
+
+func urlLabel(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	label := u.Host + strings.TrimSuffix(u.Path, "/")
+	if u.RawQuery != "" {
+		label += "?" + u.RawQuery
+	}
+	return label
+}
+
+func escapeLinkText(s string) string {
+	return strings.NewReplacer(`[`, `\[`, `]`, `\]`).Replace(s)
+}
+
+func slug(s string) string {
+	return strings.Trim(slugStrip.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+func uniqueSlug(s string, used map[string]bool) string {
+	id := s
+	for n := 2; used[id]; n++ {
+		id = s + "-" + strconv.Itoa(n)
+	}
+	used[id] = true
+	return id
+}
+
+// urlDepth counts the path segments of a URL.
+func urlDepth(rawURL string) int {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+	return len(strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' }))
+}
+
+// compareURLTree orders URLs by host, then path segment by segment, so a
+// page comes right before the pages beneath it.
+func compareURLTree(a, b string) int {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	if c := strings.Compare(ua.Host, ub.Host); c != 0 {
+		return c
+	}
+	sa := strings.FieldsFunc(ua.Path, func(r rune) bool { return r == '/' })
+	sb := strings.FieldsFunc(ub.Path, func(r rune) bool { return r == '/' })
+	if c := slices.Compare(sa, sb); c != 0 {
+		return c
+	}
+	return strings.Compare(ua.RawQuery, ub.RawQuery)
+}
@@ -0,0 +1,110 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+func TestWriteCombined(t *testing.T) {
+	results := []scraper.Result{
+		{URL: "https://example.com/guide/install", Markdown: "# Install\n\nRun the [installer](intro#setup).\n\n## Linux\n\nUse apt."},
+		{URL: "https://example.com/", Meta: scraper.Metadata{Title: "Example [Docs]"}, Markdown: "Welcome. See the [guide](/guide/install) and [elsewhere](https://other.example/)."},
+		{URL: "https://example.com/guide/intro", Markdown: "# Intro\n\nSetup\n-----\n\n```\n# not a heading\n```"},
+		{URL: "https://example.com/broken", Err: os.ErrNotExist},
+	}
+	path := filepath.Join(t.TempDir(), "all.md")
+	if err := WriteCombined(results, path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Example [Docs]
+
+## Contents
+
+- [Example \[Docs\]](#page-example-com)
+    - [Install](#page-example-com-guide-install)
+    - [Intro](#page-example-com-guide-intro)
+
+---
+
+<a id="page-example-com"></a>
+
+## Example [Docs]
+
+> Source: <https://example.com/>
+
+Welcome. See the [guide](#page-example-com-guide-install) and [elsewhere](https://other.example/).
+
+---
+
+<a id="page-example-com-guide-install"></a>
+
+## Install
+
+> Source: <https://example.com/guide/install>
+
+Run the [installer](#page-example-com-guide-intro).
+
+### Linux
+
+Use apt.
+
+---
+
+<a id="page-example-com-guide-intro"></a>
+
+## Intro
+
+> Source: <https://example.com/guide/intro>
+
+### Setup
+
+` + "```\n# not a heading\n```\n"
+	if string(got) != want {
+		t.Errorf("WriteCombined wrote:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUniqueSlug(t *testing.T) {
+	used := make(map[string]bool)
+	for _, want := range []string{"page-a", "page-a-2", "page-a-3"} {
+		if got := uniqueSlug("page-a", used); got != want {
+			t.Errorf("uniqueSlug = %q, want %q", got, want)
+		}
+	}
+	tests := []struct {
+		in, want string
+	}{
+		{"example.com/Guide/Getting Started", "example-com-guide-getting-started"},
+		{"--Hello, World!--", "hello-world"},
+	}
+	for _, tt := range tests {
+		if got := slug(tt.in); got != tt.want {
+			t.Errorf("slug(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDemoteHeadings(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"# A\n\ntext", "## A\n\ntext"},
+		{"###### Six", "###### Six"},
+		{"Title\n=====", "## Title\n"},
+		{"Sub\n---", "### Sub\n"},
+		{"text\n\n---\n", "text\n\n---\n"},
+		{"```\n# code\n```", "```\n# code\n```"},
+	}
+	for _, tt := range tests {
+		if got := demoteHeadings(tt.in); got != tt.want {
+			t.Errorf("demoteHeadings(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+// frontmatter returns the YAML frontmatter block for a result, including
+// the trailing "---" line. url and source always come first; metadata the
+// page did not provide is omitted.
+func frontmatter(r scraper.Result) string {
+	var b strings.Builder
+	m := r.Meta
+
+	b.WriteString("---\n")
+	field(&b, "url", r.URL)
+	field(&b, "source", r.Source)
+	field(&b, "title", m.Title)
+	field(&b, "description", m.Description)
+	field(&b, "canonical", m.Canonical)
+	field(&b, "language", m.Language)
+	if m.FinalURL != r.URL {
+		field(&b, "final_url", m.FinalURL)
+	}
+	listField(&b, "aliases", r.Aliases)
+	if m.StatusCode != 0 {
+		fmt.Fprintf(&b, "status: %d\n", m.StatusCode)
+	}
+	field(&b, "content_type", m.ContentType)
+	if m.Size != 0 {
+		fmt.Fprintf(&b, "size: %d\n", m.Size)
+	}
+	if m.Duration != 0 {
+		field(&b, "duration", m.Duration.Round(time.Millisecond).String())
+	}
+	if !m.Published.IsZero() {
+		field(&b, "published", m.Published.Format(time.RFC3339))
+	}
+	if !m.Modified.IsZero() {
+		field(&b, "modified", m.Modified.Format(time.RFC3339))
+	}
+	mapField(&b, "open_graph", m.OpenGraph)
+	mapField(&b, "twitter", m.Twitter)
+	b.WriteString("---\n")
+	return b.String()
+}
+
+func field(b *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s: %s\n", key, yamlString(value))
+}
+
+func listField(b *strings.Builder, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, v := range values {
+		fmt.Fprintf(b, "  - %s\n", yamlString(v))
+	}
+}
+
+func mapField(b *strings.Builder, key string, m map[string]string) {
+	if len(m) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		fmt.Fprintf(b, "  %s: %s\n", yamlString(k), yamlString(m[k]))
+	}
+}
+
+// yamlString returns s as a YAML scalar, quoting it when a plain scalar
+// would be misread. Double-quoted YAML accepts Go's escape sequences.
+func yamlString(s string) string {
+	plain := s != "" &&
+		!strings.ContainsAny(s, "\"'\n\r\t\\{}[],&*!|>%@`") &&
+		!strings.ContainsAny(s[:1], " -?:#") &&
+		!strings.Contains(s, ": ") &&
+		!strings.Contains(s, " #") &&
+		!strings.HasSuffix(s, ":") &&
+		!strings.HasSuffix(s, " ")
+	if plain {
+		switch strings.ToLower(s) {
+		case "true", "false", "yes", "no", "on", "off", "null", "~":
+			plain = false
+		}
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			plain = false
+		}
+	}
+	if plain {
+		return s
+	}
+	return strconv.Quote(s)
+}
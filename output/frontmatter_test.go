@@ -0,0 +1,34 @@
+package output
+
+import "testing"
+
+func TestYAMLString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Getting started", "Getting started"},
+		{"https://example.com/a?b=c", "https://example.com/a?b=c"},
+		{"", `""`},
+		{"true", `"true"`},
+		{"No", `"No"`},
+		{"null", `"null"`},
+		{"42", `"42"`},
+		{"1.5e3", `"1.5e3"`},
+		{"Title: subtitle", `"Title: subtitle"`},
+		{"ends with colon:", `"ends with colon:"`},
+		{"- list-like", `"- list-like"`},
+		{"#hashtag", `"#hashtag"`},
+		{"C# vs F #sharp", `"C# vs F #sharp"`},
+		{"it's", `"it's"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"two\nlines", `"two\nlines"`},
+		{"[draft] post", `"[draft] post"`},
+		{"trailing ", `"trailing "`},
+		{"@user", `"@user"`},
+	}
+	for _, tt := range tests {
+		if got := yamlString(tt.in); got != tt.want {
+			t.Errorf("yamlString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
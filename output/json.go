@@ -0,0 +1,127 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+// jsonResult is the serialized form of a scraper.Result. Metadata fields are
+// inlined so pipelines can select them directly, e.g. `jq .title`.
+type jsonResult struct {
+	URL      string            `json:"url"`
+	Source   string            `json:"source,omitempty"`
+	Markdown string            `json:"markdown,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Assets   map[string]string `json:"assets,omitempty"`
+	Aliases  []string          `json:"aliases,omitempty"`
+	scraper.Metadata
+	Duration string `json:"duration,omitempty"` // shadows Metadata.Duration with a readable value
+}
+
+// jsonAlias records that a duplicate URL was collapsed into a page already
+// written to the stream, whose "aliases" could no longer be amended.
+type jsonAlias struct {
+	URL     string `json:"url"`
+	AliasOf string `json:"alias_of"`
+}
+
+func toJSONResult(r scraper.Result) jsonResult {
+	jr := jsonResult{
+		URL:      r.URL,
+		Source:   r.Source,
+		Markdown: r.Markdown,
+		Assets:   r.Assets,
+		Aliases:  r.Aliases,
+		Metadata: r.Meta,
+	}
+	if r.Err != nil {
+		jr.Error = r.Err.Error()
+	}
+	if r.Meta.Duration != 0 {
+		jr.Duration = r.Meta.Duration.Round(time.Millisecond).String()
+	}
+	return jr
+}
+
+// JSONWriter streams results as JSON, one object per line. In array mode
+// the lines are wrapped in [ and ] so the whole stream is one JSON document;
+// otherwise it is JSON Lines. It is safe for concurrent use.
+type JSONWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	array bool
+	n     int
+	err   error
+}
+
+func NewJSONWriter(w io.Writer, array bool) *JSONWriter {
+	return &JSONWriter{w: w, array: array}
+}
+
+// Write encodes r. After the first error every call returns it.
+func (jw *JSONWriter) Write(r scraper.Result) error {
+	return jw.writeValue(toJSONResult(r), r.URL)
+}
+
+// Alias writes a record marking alias as a duplicate of the page written
+// for keptURL.
+func (jw *JSONWriter) Alias(alias, keptURL string) error {
+	return jw.writeValue(jsonAlias{URL: alias, AliasOf: keptURL}, alias)
+}
+
+// writeValue encodes v as the next element of the stream; label names it
+// in errors.
+func (jw *JSONWriter) writeValue(v any, label string) error {
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+	if jw.err != nil {
+		return jw.err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		jw.err = fmt.Errorf("failed to encode %s: %w", label, err)
+		return jw.err
+	}
+
+	prefix := ""
+	if jw.array {
+		prefix = ",\n"
+		if jw.n == 0 {
+			prefix = "[\n"
+		}
+	}
+	if _, err := fmt.Fprintf(jw.w, "%s%s", prefix, data); err != nil {
+		jw.err = err
+		return err
+	}
+	if !jw.array {
+		if _, err := io.WriteString(jw.w, "\n"); err != nil {
+			jw.err = err
+			return err
+		}
+	}
+	jw.n++
+	return nil
+}
+
+// Close terminates the stream; in array mode it writes the closing bracket
+// (or an empty array if nothing was written).
+func (jw *JSONWriter) Close() error {
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+	if jw.err != nil || !jw.array {
+		return jw.err
+	}
+	end := "\n]\n"
+	if jw.n == 0 {
+		end = "[]\n"
+	}
+	_, jw.err = io.WriteString(jw.w, end)
+	return jw.err
+}
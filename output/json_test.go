@@ -0,0 +1,66 @@
+package output
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+func TestJSONWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		array bool
+		want  string
+	}{
+		{
+			name: "lines",
+			want: `{"url":"https://example.com/a","markdown":"# A"}
+{"url":"https://example.com/b","error":"boom"}
+{"url":"https://example.com/a/","alias_of":"https://example.com/a"}
+`,
+		},
+		{
+			name:  "array",
+			array: true,
+			want: `[
+{"url":"https://example.com/a","markdown":"# A"},
+{"url":"https://example.com/b","error":"boom"},
+{"url":"https://example.com/a/","alias_of":"https://example.com/a"}
+]
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			jw := NewJSONWriter(&b, tt.array)
+			writes := []error{
+				jw.Write(scraper.Result{URL: "https://example.com/a", Markdown: "# A"}),
+				jw.Write(scraper.Result{URL: "https://example.com/b", Err: errors.New("boom")}),
+				// A duplicate found after its page was written.
+				jw.Alias("https://example.com/a/", "https://example.com/a"),
+				jw.Close(),
+			}
+			for _, err := range writes {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if b.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONWriterEmptyArray(t *testing.T) {
+	var b strings.Builder
+	if err := NewJSONWriter(&b, true).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "[]\n" {
+		t.Errorf("got %q, want %q", b.String(), "[]\n")
+	}
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Output directory layouts.
+const (
+	LayoutFlat = "flat" // one dash-joined file per page: host-path.md
+	LayoutTree = "tree" // mirrors the site: host/path/index.md
+)
+
+// AssetsDir is the directory within the output directory that holds
+// downloaded images.
+const AssetsDir = "assets"
+
+// ManifestName is the file in a tree layout output directory that maps
+// every saved URL to its file, relative to the directory.
+const ManifestName = "manifest.json"
+
+// pagePath returns where a page is saved, relative to the output directory.
+func pagePath(rawURL, layout string) string {
+	if layout == LayoutTree {
+		return urlToTreePath(rawURL)
+	}
+	return urlToFilename(rawURL)
+}
+
+// urlToTreePath maps a URL to host/path/index.md. Pages with a query
+// string, or whose path had to be altered to be a safe file name, get
+// index-<hash>.md instead so they never overwrite each other.
+func urlToTreePath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return filepath.Join("unknown", "index-"+shortHash(rawURL)+".md")
+	}
+
+	parts := []string{safeSegment(u.Host)}
+	altered := false
+	for seg := range strings.SplitSeq(u.EscapedPath(), "/") {
+		if seg == "" {
+			continue
+		}
+		dec, err := url.PathUnescape(seg)
+		if err != nil {
+			dec = seg
+		}
+		safe := safeSegment(dec)
+		if safe != dec {
+			altered = true
+		}
+		parts = append(parts, safe)
+	}
+
+	name := "index.md"
+	if u.RawQuery != "" || altered {
+		name = "index-" + shortHash(u.EscapedPath()+"?"+u.RawQuery) + ".md"
+	}
+	return filepath.Join(append(parts, name)...)
+}
+
+// safeSegment makes s usable as a single path element on common file
+// systems. Names that could clash with the generated index files are
+// prefixed so a page directory never collides with a page file.
+func safeSegment(s string) string {
+	if s == "." || s == ".." {
+		return "_" + s
+	}
+	if strings.HasPrefix(s, "index") && strings.HasSuffix(s, ".md") {
+		return "_" + s
+	}
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:4])
+}
+
+// validLayout reports an error for unknown layout names.
+func validLayout(layout string) error {
+	switch layout {
+	case LayoutFlat, LayoutTree:
+		return nil
+	}
+	return fmt.Errorf("invalid layout %q: must be %s or %s", layout, LayoutFlat, LayoutTree)
+}
@@ -0,0 +1,193 @@
+package output
+
+import (
+	"cmp"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+var (
+	// inlineDestRe matches the destination of an inline link or image,
+	// [text](dest "title"), capturing everything up to the destination.
+	inlineDestRe = regexp.MustCompile(`(\]\(\s*)(<[^>\n]*>|[^)\s]+)`)
+	// refDestRe matches a reference definition, [label]: dest.
+	refDestRe = regexp.MustCompile(`^( {0,3}\[[^\]]+\]:[ \t]*)(<[^>\n]*>|\S+)`)
+)
+
+// localizeLinks rewrites links in every saved page that point to another
+// saved page into relative paths to its file. Fragments are kept; links to
+// pages that were not scraped stay absolute. The caller holds fw.mu.
+func (fw *FileWriter) localizeLinks() {
+	if len(fw.pages) < 2 {
+		return
+	}
+
+	files := make(map[string]string, len(fw.pages)*2)
+	for _, p := range fw.pages {
+		files[scraper.CanonicalURL(p.url)] = p.rel
+	}
+	// Redirect targets and aliases resolve to the same file, unless
+	// scraped themselves.
+	for _, p := range fw.pages {
+		for _, u := range append([]string{p.final}, p.aliases...) {
+			if _, ok := files[scraper.CanonicalURL(u)]; !ok {
+				files[scraper.CanonicalURL(u)] = p.rel
+			}
+		}
+	}
+
+	for _, p := range fw.pages {
+		base, err := url.Parse(p.final)
+		if err != nil {
+			continue
+		}
+		from := filepath.Dir(p.rel)
+		local := func(dest string) (string, bool) {
+			u, err := url.Parse(dest)
+			if err != nil {
+				return "", false
+			}
+			abs := base.ResolveReference(u)
+			frag := abs.Fragment
+			target, ok := files[scraper.CanonicalURL(abs.String())]
+			if !ok {
+				return "", false
+			}
+			return relativeLink(from, target, frag), true
+		}
+
+		path := filepath.Join(fw.dir, p.rel)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fw.logf("Error rewriting links in %s: %v\n", path, err)
+			continue
+		}
+		fm, body := splitFrontmatter(string(data))
+		out := fm + rewriteLinks(body, local)
+		if out == string(data) {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+			fw.logf("Error rewriting links in %s: %v\n", path, err)
+		}
+	}
+}
+
+// localizeAssets points image references in r's markdown at the files
+// downloaded for it, as seen from the directory from.
+func localizeAssets(r scraper.Result, from string) string {
+	base, err := url.Parse(cmp.Or(r.Meta.FinalURL, r.URL))
+	if err != nil {
+		return r.Markdown
+	}
+	return rewriteLinks(r.Markdown, func(dest string) (string, bool) {
+		u, err := url.Parse(dest)
+		if err != nil {
+			return "", false
+		}
+		name, ok := r.Assets[base.ResolveReference(u).String()]
+		if !ok {
+			return "", false
+		}
+		return relativeLink(from, filepath.Join(AssetsDir, name), ""), true
+	})
+}
+
+// relativeLink returns a markdown destination for target as seen from the
+// directory from, both relative to the output directory.
+func relativeLink(from, target, fragment string) string {
+	rel, err := filepath.Rel(from, target)
+	if err != nil {
+		rel = target
+	}
+	segs := strings.Split(filepath.ToSlash(rel), "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	link := path.Join(segs...)
+	// "./" keeps names like host:port-page.md from parsing as a scheme.
+	if !strings.HasPrefix(link, "../") {
+		link = "./" + link
+	}
+	if fragment != "" {
+		link += "#" + fragment
+	}
+	return link
+}
+
+// rewriteLinks replaces link destinations in md for which replace returns
+// true. Fenced code blocks are left untouched.
+func rewriteLinks(md string, replace func(dest string) (string, bool)) string {
+	sub := func(m string, re *regexp.Regexp) string {
+		g := re.FindStringSubmatch(m)
+		dest := strings.TrimSuffix(strings.TrimPrefix(g[2], "<"), ">")
+		if repl, ok := replace(dest); ok {
+			return g[1] + repl
+		}
+		return m
+	}
+	return mapProse(md, func(line string) string {
+		line = inlineDestRe.ReplaceAllStringFunc(line, func(m string) string {
+			return sub(m, inlineDestRe)
+		})
+		return refDestRe.ReplaceAllStringFunc(line, func(m string) string {
+			return sub(m, refDestRe)
+		})
+	})
+}
+
+// mapProse applies fn to every line of md outside fenced code blocks.
+// Lines keep their trailing newline.
+func mapProse(md string, fn func(line string) string) string {
+	lines := strings.SplitAfter(md, "\n")
+	var fences fenceState
+	for i, line := range lines {
+		if !fences.code(line) {
+			lines[i] = fn(line)
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// fenceState follows a line-by-line scan in and out of fenced code blocks.
+type fenceState struct {
+	fence string // opening marker while inside a block
+}
+
+// code reports whether line belongs to a fenced code block, counting the
+// fence lines themselves.
+func (f *fenceState) code(line string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if f.fence != "" {
+		if strings.HasPrefix(trimmed, f.fence) {
+			f.fence = ""
+		}
+		return true
+	}
+	if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+		f.fence = trimmed[:3]
+		return true
+	}
+	return false
+}
+
+// splitFrontmatter separates a leading YAML frontmatter block, including
+// its closing "---" line, from the markdown after it.
+func splitFrontmatter(page string) (fm, body string) {
+	rest, ok := strings.CutPrefix(page, "---\n")
+	if !ok {
+		return "", page
+	}
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		return "", page
+	}
+	n := len("---\n") + end + len("\n---\n")
+	return page[:n], page[n:]
+}
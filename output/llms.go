@@ -0,0 +1,146 @@
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+// WriteLLMsTxt writes llms.txt and llms-full.txt (https://llmstxt.org) to
+// dir. llms.txt indexes the pages by section with their descriptions;
+// llms-full.txt holds the full markdown of every page.
+func WriteLLMsTxt(results []scraper.Result, dir string) error {
+	var pages []scraper.Result
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "Error scraping %s: %v\n", r.URL, r.Err)
+			continue
+		}
+		pages = append(pages, r)
+	}
+	if len(pages) == 0 {
+		return fmt.Errorf("no pages were scraped; nothing to write to llms.txt")
+	}
+	slices.SortStableFunc(pages, func(a, b scraper.Result) int {
+		return compareURLTree(a.URL, b.URL)
+	})
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// The first page in tree order is the site root when it was scraped.
+	root := pages[0]
+	title := cmp.Or(root.Meta.OpenGraph["site_name"], pageLabel(root))
+	header := "# " + oneLine(title) + "\n"
+	if summary := oneLine(root.Meta.Description); summary != "" {
+		header += "\n> " + summary + "\n"
+	}
+
+	// Sections appear in the order their first page does.
+	var (
+		order    []string
+		sections = make(map[string][]scraper.Result)
+	)
+	multiHost := hostCount(pages) > 1
+	for _, r := range pages {
+		s := llmsSection(r.URL, multiHost)
+		if _, ok := sections[s]; !ok {
+			order = append(order, s)
+		}
+		sections[s] = append(sections[s], r)
+	}
+
+	var index strings.Builder
+	index.WriteString(header)
+	for _, s := range order {
+		fmt.Fprintf(&index, "\n## %s\n\n", s)
+		for _, r := range sections[s] {
+			fmt.Fprintf(&index, "- [%s](%s)", escapeLinkText(oneLine(pageLabel(r))), r.URL)
+			if desc := oneLine(r.Meta.Description); desc != "" {
+				fmt.Fprintf(&index, ": %s", desc)
+			}
+			index.WriteString("\n")
+		}
+	}
+
+	var full strings.Builder
+	full.WriteString(header)
+	for _, r := range pages {
+		md := strings.TrimSpace(r.Markdown)
+		heading := "# " + oneLine(pageLabel(r))
+		if first, rest, _ := strings.Cut(md, "\n"); isH1(first) {
+			heading, md = first, strings.TrimLeft(rest, "\n")
+		}
+		full.WriteString("\n---\n\n")
+		full.WriteString(demoteHeadings(heading))
+		fmt.Fprintf(&full, "\n\nSource: %s\n\n", r.URL)
+		full.WriteString(demoteHeadings(md))
+		full.WriteString("\n")
+	}
+
+	files := []struct{ name, content string }{
+		{"llms.txt", index.String()},
+		{"llms-full.txt", full.String()},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, []byte(f.content), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		fmt.Fprintf(os.Stderr, "Saved: %s\n", path)
+	}
+	return nil
+}
+
+// llmsSection groups a page under its top-level path segment, so /docs/...
+// and /blog/... get their own sections. Pages at the top of the site are
+// listed under "Pages".
+func llmsSection(rawURL string, withHost bool) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "Pages"
+	}
+	segs := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	name := "Pages"
+	if len(segs) > 1 || (len(segs) == 1 && strings.HasSuffix(u.Path, "/")) {
+		name = humanize(segs[0])
+	}
+	if withHost {
+		name = u.Host + ": " + name
+	}
+	return name
+}
+
+// humanize turns a path segment like "getting-started" into
+// "Getting started".
+func humanize(seg string) string {
+	if s, err := url.PathUnescape(seg); err == nil {
+		seg = s
+	}
+	seg = strings.NewReplacer("-", " ", "_", " ").Replace(seg)
+	if seg == "" {
+		return seg
+	}
+	return strings.ToUpper(seg[:1]) + seg[1:]
+}
+
+func hostCount(pages []scraper.Result) int {
+	hosts := make(map[string]bool)
+	for _, r := range pages {
+		if u, err := url.Parse(r.URL); err == nil {
+			hosts[u.Host] = true
+		}
+	}
+	return len(hosts)
+}
+
+// oneLine collapses whitespace so a value fits on a single markdown line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+func TestWriteLLMsTxt(t *testing.T) {
+	results := []scraper.Result{
+		{
+			URL:      "https://example.com/docs/getting-started",
+			Meta:     scraper.Metadata{Title: "Getting started", Description: "Install and\nrun it."},
+			Markdown: "# Getting started\n\n## Install\n\nRun it.",
+		},
+		{
+			URL: "https://example.com/",
+			Meta: scraper.Metadata{
+				Title:       "Home",
+				Description: "Example builds tools.",
+				OpenGraph:   map[string]string{"site_name": "Example"},
+			},
+			Markdown: "Welcome.",
+		},
+		{URL: "https://example.com/blog/", Markdown: "# The [blog]\n\nPosts."},
+		{URL: "https://example.com/about", Markdown: "About us."},
+		{URL: "https://example.com/gone", Err: os.ErrNotExist},
+	}
+	dir := t.TempDir()
+	if err := WriteLLMsTxt(results, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "llms.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantIndex := `# Example
+
+> Example builds tools.
+
+## Pages
+
+- [Home](https://example.com/): Example builds tools.
+- [example.com/about](https://example.com/about)
+
+## Blog
+
+- [The \[blog\]](https://example.com/blog/)
+
+## Docs
+
+- [Getting started](https://example.com/docs/getting-started): Install and run it.
+`
+	if string(index) != wantIndex {
+		t.Errorf("llms.txt:\n%s\nwant:\n%s", index, wantIndex)
+	}
+
+	full, err := os.ReadFile(filepath.Join(dir, "llms-full.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFull := `# Example
+
+> Example builds tools.
+
+---
+
+## Home
+
+Source: https://example.com/
+
+Welcome.
+
+---
+
+## example.com/about
+
+Source: https://example.com/about
+
+About us.
+
+---
+
+## The [blog]
+
+Source: https://example.com/blog/
+
+Posts.
+
+---
+
+## Getting started
+
+Source: https://example.com/docs/getting-started
+
+### Install
+
+Run it.
+`
+	if string(full) != wantFull {
+		t.Errorf("llms-full.txt:\n%s\nwant:\n%s", full, wantFull)
+	}
+}
+
+func TestWriteLLMsTxtNoPages(t *testing.T) {
+	err := WriteLLMsTxt([]scraper.Result{{URL: "https://example.com/", Err: os.ErrNotExist}}, t.TempDir())
+	if err == nil {
+		t.Error("WriteLLMsTxt with no successful pages: want an error")
+	}
+}
+
+func TestLLMsSection(t *testing.T) {
+	tests := []struct {
+		url      string
+		withHost bool
+		want     string
+	}{
+		{"https://example.com/", false, "Pages"},
+		{"https://example.com/about", false, "Pages"},
+		{"https://example.com/docs/", false, "Docs"},
+		{"https://example.com/getting-started/install", false, "Getting started"},
+		{"https://example.com/api_ref/v1", false, "Api ref"},
+		{"https://example.com/docs/x", true, "example.com: Docs"},
+	}
+	for _, tt := range tests {
+		if got := llmsSection(tt.url, tt.withHost); got != tt.want {
+			t.Errorf("llmsSection(%q, %v) = %q, want %q", tt.url, tt.withHost, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package output
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/Gaurav-Gosain/scraped/scraper"
 	"charm.land/glamour/v2"
@@ -39,7 +43,8 @@ func RenderTerminal(results []scraper.Result, wordWrap int) error {
 }
 
 // WriteRaw writes plain markdown to stdout with no ANSI formatting.
-// Pages are separated by --- with YAML frontmatter containing the URL and source.
+// Pages are separated by --- with YAML frontmatter containing the URL, source
+// and page metadata.
 func WriteRaw(results []scraper.Result) error {
 	first := true
 	for _, r := range results {
@@ -50,36 +55,214 @@ func WriteRaw(results []scraper.Result) error {
 		if !first {
 			fmt.Println()
 		}
-		fmt.Printf("---\nurl: %s\nsource: %s\n---\n\n%s\n", r.URL, r.Source, r.Markdown)
+		fmt.Printf("%s\n%s\n", frontmatter(r), r.Markdown)
 		first = false
 	}
 	return nil
 }
 
-// WriteFiles writes each result as a .md file in the given directory.
-func WriteFiles(results []scraper.Result, dir string) error {
+// WriteFiles writes each result as a .md file in the given directory, with
+// the same frontmatter as WriteRaw.
+func WriteFiles(results []scraper.Result, dir, layout string) error {
+	fw, err := NewFileWriter(dir, layout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		fw.Write(r)
+	}
+	return fw.Close()
+}
+
+// FileWriter writes results to .md files one at a time, so a streaming
+// scrape can save each page as soon as it is converted. It is safe for
+// concurrent use.
+type FileWriter struct {
+	dir    string
+	layout string    // LayoutFlat or LayoutTree
+	log    io.Writer // progress and errors, nil = silent
+
+	// RemoveBoilerplate strips blocks repeated across a host's pages from
+	// the saved files when the writer is closed.
+	RemoveBoilerplate bool
+
+	mu          sync.Mutex
+	saved       int
+	manifest    map[string]string   // URL → file relative to dir
+	aliases     map[string][]string // saved page URL → duplicate URLs collapsed into it
+	pages       []savedPage
+	boilerplate []BoilerplateBlock // removed by Close
+}
+
+// savedPage remembers a written file so Close can rewrite its links.
+type savedPage struct {
+	url     string            // as requested
+	final   string            // after redirects, the base for relative links
+	aliases []string          // duplicate URLs collapsed into this page
+	rel     string            // file relative to dir
+	assets  map[string]string // images still to localize, when Close removes boilerplate
+}
+
+// NewFileWriter creates dir if needed. Progress lines ("Saved: ...") and
+// per-page errors are written to log unless it is nil.
+func NewFileWriter(dir, layout string, log io.Writer) (*FileWriter, error) {
+	if err := validLayout(layout); err != nil {
+		return nil, err
+	}
 	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
+	return &FileWriter{
+		dir:      dir,
+		layout:   layout,
+		log:      log,
+		manifest: make(map[string]string),
+		aliases:  make(map[string][]string),
+	}, nil
+}
 
-	for _, r := range results {
-		if r.Err != nil {
-			fmt.Fprintf(os.Stderr, "Error scraping %s: %v\n", r.URL, r.Err)
-			continue
+// Write saves r. Failed scrapes and write errors are reported to the log
+// rather than returned so one bad page does not stop the rest.
+func (fw *FileWriter) Write(r scraper.Result) {
+	if r.Err != nil {
+		fw.logf("Error scraping %s: %v\n", r.URL, r.Err)
+		return
+	}
+
+	rel := pagePath(r.URL, fw.layout)
+	path := filepath.Join(fw.dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fw.logf("Error writing %s: %v\n", path, err)
+		return
+	}
+	// Image paths differ with each page's directory, so with boilerplate
+	// removal they are localized by Close, after blocks such as a logo have
+	// been compared across pages.
+	md := r.Markdown
+	var assets map[string]string
+	if len(r.Assets) > 0 {
+		if fw.RemoveBoilerplate {
+			assets = r.Assets
+		} else {
+			md = localizeAssets(r, filepath.Dir(rel))
 		}
+	}
+	if err := os.WriteFile(path, []byte(frontmatter(r)+"\n"+md), 0o644); err != nil {
+		fw.logf("Error writing %s: %v\n", path, err)
+		return
+	}
+	fw.mu.Lock()
+	fw.saved++
+	fw.manifest[r.URL] = filepath.ToSlash(rel)
+	fw.aliases[r.URL] = append(fw.aliases[r.URL], r.Aliases...)
+	fw.pages = append(fw.pages, savedPage{url: r.URL, final: cmp.Or(r.Meta.FinalURL, r.URL), rel: rel, assets: assets})
+	fw.mu.Unlock()
+	fw.logf("Saved: %s\n", path)
+}
 
-		filename := urlToFilename(r.URL)
-		path := filepath.Join(dir, filename)
+// Close points links between saved pages at the local files, so the
+// directory can be browsed offline, and in the tree layout writes the
+// manifest. Flat file names already spell out their URLs.
+func (fw *FileWriter) Close() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
 
-		if err := os.WriteFile(path, []byte(r.Markdown), 0o644); err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", path, err)
-			continue
+	for i, p := range fw.pages {
+		fw.pages[i].aliases = fw.aliases[p.url]
+		for _, alias := range fw.pages[i].aliases {
+			if _, ok := fw.manifest[alias]; !ok {
+				fw.manifest[alias] = filepath.ToSlash(p.rel)
+			}
 		}
-		fmt.Fprintf(os.Stderr, "Saved: %s\n", path)
+	}
+	if fw.RemoveBoilerplate {
+		fw.stripBoilerplate()
+	}
+	fw.localizeLinks()
+	if fw.layout != LayoutTree {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(fw.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	path := filepath.Join(fw.dir, ManifestName)
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
 	}
 	return nil
 }
 
+// Alias records alias as another URL of the page saved for keptURL, so the
+// manifest and rewritten links resolve it to the same file. The page need
+// not be written yet.
+func (fw *FileWriter) Alias(alias, keptURL string) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.aliases[keptURL] = append(fw.aliases[keptURL], alias)
+}
+
+// stripBoilerplate removes repeated blocks from the saved pages, reading
+// each file once to count blocks and once more to rewrite it, localizing
+// its images on the way. The caller holds fw.mu.
+func (fw *FileWriter) stripBoilerplate() {
+	b := newBoilerplate()
+	for _, p := range fw.pages {
+		data, err := os.ReadFile(filepath.Join(fw.dir, p.rel))
+		if err != nil {
+			continue
+		}
+		_, body := splitFrontmatter(string(data))
+		b.add(pageHost(p.final), body)
+	}
+	for _, p := range fw.pages {
+		path := filepath.Join(fw.dir, p.rel)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fw.logf("Error removing boilerplate from %s: %v\n", path, err)
+			continue
+		}
+		fm, body := splitFrontmatter(string(data))
+		stripped := b.strip(pageHost(p.final), body)
+		if len(p.assets) > 0 {
+			stripped = localizeAssets(scraper.Result{
+				URL:      p.url,
+				Markdown: stripped,
+				Meta:     scraper.Metadata{FinalURL: p.final},
+				Assets:   p.assets,
+			}, filepath.Dir(p.rel))
+		}
+		if stripped == body {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(fm+stripped), 0o644); err != nil {
+			fw.logf("Error removing boilerplate from %s: %v\n", path, err)
+		}
+	}
+	fw.boilerplate = b.report()
+}
+
+// Boilerplate returns the blocks Close removed from the saved pages.
+func (fw *FileWriter) Boilerplate() []BoilerplateBlock {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.boilerplate
+}
+
+// Saved returns how many files have been written.
+func (fw *FileWriter) Saved() int {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.saved
+}
+
+func (fw *FileWriter) logf(format string, args ...any) {
+	if fw.log != nil {
+		fmt.Fprintf(fw.log, format, args...)
+	}
+}
+
 // urlToFilename converts a URL to a safe filename.
 func urlToFilename(rawURL string) string {
 	u, err := url.Parse(rawURL)
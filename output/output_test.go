@@ -0,0 +1,124 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gaurav-Gosain/scraped/scraper"
+)
+
+func TestFileWriterManifest(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   map[string]string // nil = no manifest
+	}{
+		{layout: LayoutFlat},
+		{
+			layout: LayoutTree,
+			want: map[string]string{
+				"https://example.com/docs/":          "example.com/docs/index.md",
+				"https://example.com/docs/index.htm": "example.com/docs/index.md",
+				"https://example.com/":               "example.com/index.md",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			dir := t.TempDir()
+			fw, err := NewFileWriter(dir, tt.layout, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write(scraper.Result{URL: "https://example.com/", Markdown: "# Home"})
+			fw.Write(scraper.Result{URL: "https://example.com/docs/", Markdown: "# Docs"})
+			fw.Write(scraper.Result{URL: "https://example.com/broken", Err: errors.New("boom")})
+			fw.Alias("https://example.com/docs/index.htm", "https://example.com/docs/")
+			if err := fw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if fw.Saved() != 2 {
+				t.Errorf("Saved() = %d, want 2", fw.Saved())
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+			if tt.want == nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("%s layout wrote %s: %v", tt.layout, ManifestName, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]string
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("manifest = %v, want %v", got, tt.want)
+			}
+			for u, rel := range tt.want {
+				if got[u] != rel {
+					t.Errorf("manifest[%s] = %q, want %q", u, got[u], rel)
+				}
+			}
+		})
+	}
+}
+
+func TestFileWriterBoilerplateImages(t *testing.T) {
+	// The logo repeats on every page. In the tree layout its local path
+	// differs with each page's depth, which must not hide the repetition.
+	dir := t.TempDir()
+	fw, err := NewFileWriter(dir, LayoutTree, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.RemoveBoilerplate = true
+	assets := map[string]string{
+		"https://example.com/logo.png":  "logo-1234.png",
+		"https://example.com/chart.png": "chart-5678.png",
+	}
+	pages := []string{"https://example.com/", "https://example.com/a/", "https://example.com/a/b/", "https://example.com/c/d/e/"}
+	for i, u := range pages {
+		md := fmt.Sprintf("![logo](/logo.png)\n\nPage %d text.\n", i)
+		if i == 0 {
+			md += "\n![chart](https://example.com/chart.png)\n"
+		}
+		fw.Write(scraper.Result{URL: u, Markdown: md, Assets: assets})
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := fw.Boilerplate()
+	if len(blocks) != 1 || blocks[0].Text != "![logo](/logo.png)" || blocks[0].Pages != 4 {
+		t.Errorf("Boilerplate() = %+v, want the logo removed from 4 pages", blocks)
+	}
+	for i, u := range pages {
+		data, err := os.ReadFile(filepath.Join(dir, pagePath(u, LayoutTree)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, body := splitFrontmatter(string(data))
+		if strings.Contains(body, "logo") {
+			t.Errorf("%s still has the logo:\n%s", u, body)
+		}
+		if !strings.Contains(body, fmt.Sprintf("Page %d text.", i)) {
+			t.Errorf("%s lost its text:\n%s", u, body)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, pagePath(pages[0], LayoutTree)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "![chart](../assets/chart-5678.png)"; !strings.Contains(string(data), want) {
+		t.Errorf("first page does not link the local chart %q:\n%s", want, data)
+	}
+}
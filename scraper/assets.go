@@ -0,0 +1,212 @@
+package scraper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// defaultMaxAssetSize caps a single downloaded asset when
+// Options.MaxAssetSize is unset.
+const defaultMaxAssetSize = 10 << 20
+
+var errAssetTooLarge = errors.New("asset exceeds size limit")
+
+// assetFetcher downloads the images pages reference into one directory,
+// naming each file after a hash of its content so identical images are
+// stored once. Each URL is fetched at most once per run.
+type assetFetcher struct {
+	client  *http.Client
+	dir     string
+	maxSize int64
+	allow   func(u *url.URL) bool
+
+	mu      sync.Mutex
+	entries map[string]*assetEntry
+}
+
+type assetEntry struct {
+	once sync.Once
+	name string
+	err  error
+}
+
+func newAssetFetcher(transport http.RoundTripper, dir string, maxSize int64, allow func(*url.URL) bool) (*assetFetcher, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create asset directory: %w", err)
+	}
+	if maxSize <= 0 {
+		maxSize = defaultMaxAssetSize
+	}
+	return &assetFetcher{
+		client:  &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		dir:     dir,
+		maxSize: maxSize,
+		allow:   allow,
+		entries: make(map[string]*assetEntry),
+	}, nil
+}
+
+// fetchAll downloads every image referenced in md and returns a map from
+// absolute image URL to file name within the asset directory. Images that
+// are off-limits or fail to download are left out and stay remote.
+func (af *assetFetcher) fetchAll(ctx context.Context, md, baseURL string) map[string]string {
+	var assets map[string]string
+	for _, src := range extractMarkdownImages(md, baseURL) {
+		u, err := url.Parse(src)
+		if err != nil || !af.allow(u) {
+			continue
+		}
+		name, err := af.fetch(ctx, src)
+		if err != nil {
+			continue
+		}
+		if assets == nil {
+			assets = make(map[string]string)
+		}
+		assets[src] = name
+	}
+	return assets
+}
+
+func (af *assetFetcher) fetch(ctx context.Context, rawURL string) (string, error) {
+	af.mu.Lock()
+	e, ok := af.entries[rawURL]
+	if !ok {
+		e = &assetEntry{}
+		af.entries[rawURL] = e
+	}
+	af.mu.Unlock()
+
+	e.once.Do(func() {
+		e.name, e.err = af.download(ctx, rawURL)
+	})
+	return e.name, e.err
+}
+
+func (af *assetFetcher) download(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "image/*,*/*;q=0.8")
+
+	resp, err := af.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > af.maxSize {
+		return "", errAssetTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, af.maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > af.maxSize {
+		return "", errAssetTooLarge
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:16]) + assetExt(rawURL, resp.Header.Get("Content-Type"))
+	dest := filepath.Join(af.dir, name)
+	if _, err := os.Stat(dest); err == nil {
+		return name, nil
+	}
+	if err := writeFileAtomic(dest, data); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// assetExt picks a file extension from the URL path, falling back to the
+// response content type.
+func assetExt(rawURL, contentType string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		ext := strings.ToLower(path.Ext(u.Path))
+		if len(ext) > 1 && len(ext) <= 6 {
+			return ext
+		}
+	}
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		if exts, _ := mime.ExtensionsByType(mt); len(exts) > 0 {
+			return exts[0]
+		}
+	}
+	return ""
+}
+
+// assetHostAllowed returns the domain rule for asset downloads: any host
+// with crossDomains, otherwise the seed hosts and the extra domains given
+// (including their subdomains).
+func assetHostAllowed(seeds, extra []string, crossDomains bool) func(*url.URL) bool {
+	seedHosts := extractDomains(seeds)
+	return func(u *url.URL) bool {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return false
+		}
+		if crossDomains {
+			return true
+		}
+		host := strings.ToLower(u.Hostname())
+		if slices.Contains(seedHosts, host) {
+			return true
+		}
+		for _, d := range extra {
+			d = strings.ToLower(strings.TrimPrefix(d, "."))
+			if host == d || strings.HasSuffix(host, "."+d) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// extractMarkdownImages returns the absolute URLs of all images in md.
+func extractMarkdownImages(md string, baseURL string) []string {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+
+	source := []byte(md)
+	doc := mdParser.Parser().Parse(text.NewReader(source))
+
+	var srcs []string
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		img, ok := n.(*ast.Image)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		u, err := url.Parse(strings.TrimSpace(string(img.Destination)))
+		if err != nil {
+			return ast.WalkContinue, nil
+		}
+		resolved := base.ResolveReference(u)
+		if !slices.Contains(srcs, resolved.String()) {
+			srcs = append(srcs, resolved.String())
+		}
+		return ast.WalkContinue, nil
+	})
+	return srcs
+}
@@ -0,0 +1,225 @@
+package scraper
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// redacted replaces credentials in URLs, events and results.
+	redacted = "xxxxx"
+	// minRedactLen is the shortest secret redacted from error messages.
+	minRedactLen = 6
+)
+
+// authConfig holds the credentials sent to specific hosts: --auth profiles
+// first, then ~/.netrc entries. A request gets an Authorization header
+// only when its host matches one of them exactly.
+type authConfig struct {
+	profiles map[string]string // host → Authorization header value
+	netrc    map[string]string // host → Authorization header value
+	secrets  []string          // passwords, tokens and header values to redact
+}
+
+// parseAuth reads --auth values of the form "host=basic:user:password" or
+// "host=bearer:ENV_VAR", where the bearer token comes from the named
+// environment variable so it never appears in the shell history.
+func parseAuth(specs []string, useNetrc bool) (*authConfig, error) {
+	a := &authConfig{profiles: make(map[string]string)}
+	for _, spec := range specs {
+		host, cred, ok := strings.Cut(spec, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		kind, rest, _ := strings.Cut(cred, ":")
+		if !ok || host == "" || rest == "" {
+			return nil, fmt.Errorf("invalid auth %q: want \"host=basic:user:password\" or \"host=bearer:ENV_VAR\"", spec)
+		}
+		switch strings.ToLower(kind) {
+		case "basic":
+			user, pass, _ := strings.Cut(rest, ":")
+			a.profiles[host] = a.basic(user, pass)
+		case "bearer":
+			token := os.Getenv(rest)
+			if token == "" {
+				return nil, fmt.Errorf("invalid auth for %s: environment variable %s is not set", host, rest)
+			}
+			a.secrets = append(a.secrets, token)
+			a.profiles[host] = "Bearer " + token
+		default:
+			return nil, fmt.Errorf("invalid auth %q: unknown scheme %q, want basic or bearer", spec, kind)
+		}
+	}
+	if useNetrc {
+		if err := a.loadNetrc(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (a *authConfig) basic(user, pass string) string {
+	header := "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	if pass != "" {
+		a.secrets = append(a.secrets, pass)
+	}
+	a.secrets = append(a.secrets, header)
+	return header
+}
+
+// loadNetrc reads $NETRC, or ~/.netrc when unset. A missing file is not
+// an error. The "default" entry is ignored: it would send credentials to
+// every host a crawl reaches.
+func (a *authConfig) loadNetrc() error {
+	path := os.Getenv("NETRC")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(home, ".netrc")
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read netrc: %w", err)
+	}
+
+	a.netrc = make(map[string]string)
+	var machine, login, password string
+	flush := func() {
+		if machine != "" && (login != "" || password != "") {
+			if _, ok := a.netrc[machine]; !ok {
+				a.netrc[machine] = a.basic(login, password)
+			}
+		}
+		machine, login, password = "", "", ""
+	}
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			tok := fields[j]
+			if strings.HasPrefix(tok, "#") {
+				break
+			}
+			next := ""
+			if j+1 < len(fields) {
+				next = fields[j+1]
+			}
+			switch tok {
+			case "machine":
+				flush()
+				machine = strings.ToLower(next)
+				j++
+			case "default":
+				flush()
+			case "login":
+				login = next
+				j++
+			case "password":
+				password = next
+				j++
+			case "account":
+				j++
+			case "macdef":
+				// A macro runs until the next blank line.
+				flush()
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+	flush()
+	return nil
+}
+
+// authorization returns the Authorization header for a request to u, or
+// "" when no credentials belong to its host.
+func (a *authConfig) authorization(u *url.URL) string {
+	if a == nil {
+		return ""
+	}
+	for _, host := range []string{strings.ToLower(u.Host), strings.ToLower(u.Hostname())} {
+		if v, ok := a.profiles[host]; ok {
+			return v
+		}
+	}
+	return a.netrc[strings.ToLower(u.Hostname())]
+}
+
+// takeURLCredentials moves user:password@ from the seed URLs into basic
+// auth profiles for their hosts, so the credentials are sent only to that
+// host and never show up in results, events or the state file.
+func takeURLCredentials(opts *Options) {
+	opts.URLs = slices.Clone(opts.URLs)
+	for i, raw := range opts.URLs {
+		u, err := url.Parse(raw)
+		if err != nil || u.User == nil {
+			continue
+		}
+		pass, _ := u.User.Password()
+		opts.Auth = append(opts.Auth, u.Host+"=basic:"+u.User.Username()+":"+pass)
+		u.User = nil
+		opts.URLs[i] = u.String()
+	}
+}
+
+// redactURL hides the password in a URL's user info.
+func redactURL(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+	}
+	return s
+}
+
+// redactErr hides known secrets in err's message. Secrets shorter than
+// minRedactLen are left alone, as replacing them would mangle ordinary
+// words; nothing scraped prints ever contains them anyway.
+func (a *authConfig) redactErr(err error) error {
+	if err == nil || a == nil {
+		return err
+	}
+	msg := err.Error()
+	for _, secret := range a.secrets {
+		if len(secret) >= minRedactLen {
+			msg = strings.ReplaceAll(msg, secret, redacted)
+		}
+	}
+	if msg != err.Error() {
+		return errors.New(msg)
+	}
+	return err
+}
+
+func (a *authConfig) redactEvent(e Event) Event {
+	e.URL = redactURL(e.URL)
+	e.Of = redactURL(e.Of)
+	e.Err = a.redactErr(e.Err)
+	return e
+}
+
+func (a *authConfig) redactResult(r Result) Result {
+	r.URL = redactURL(r.URL)
+	r.Err = a.redactErr(r.Err)
+	r.Meta.FinalURL = redactURL(r.Meta.FinalURL)
+	r.Meta.Canonical = redactURL(r.Meta.Canonical)
+	for i, alias := range r.Aliases {
+		r.Aliases[i] = redactURL(alias)
+	}
+	return r
+}
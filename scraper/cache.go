@@ -0,0 +1,272 @@
+package scraper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotCached is returned in offline mode for URLs missing from the cache.
+var ErrNotCached = errors.New("not in cache (offline)")
+
+// cacheEntry is the metadata stored next to each cached response body.
+type cacheEntry struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	StoredAt   time.Time   `json:"stored_at"`
+	// Vary holds the request's values of the headers the response varies
+	// on; a request with different values is a miss.
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// cachingTransport is an http.RoundTripper that stores GET responses on disk
+// keyed by URL, honoring Vary. Online, cached entries are revalidated with
+// If-None-Match / If-Modified-Since and a 304 is answered from disk.
+// Offline, only the cache is consulted and the network is never touched.
+//
+// Requests carrying credentials (Authorization, Cookie or one of the
+// private headers, such as an API key given with --header) bypass the cache
+// online, and Set-Cookie is never stored, so the plaintext cache only ever
+// holds what an anonymous client could fetch.
+type cachingTransport struct {
+	dir     string
+	offline bool
+	private []string // header names whose presence makes a request personal
+	next    http.RoundTripper
+}
+
+func newCachingTransport(dir string, offline bool, private []string, next http.RoundTripper) (*cachingTransport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &cachingTransport{dir: dir, offline: offline, private: private, next: next}, nil
+}
+
+// paths returns the metadata and body file paths for a URL.
+func (t *cachingTransport) paths(rawURL string) (meta, body string) {
+	sum := sha256.Sum256([]byte(rawURL))
+	key := hex.EncodeToString(sum[:])
+	base := filepath.Join(t.dir, key[:2], key)
+	return base + ".json", base + ".body"
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		if t.offline {
+			return nil, ErrNotCached
+		}
+		return t.next.RoundTrip(req)
+	}
+
+	if !t.offline && t.hasCredentials(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	entry, body, cached := t.load(key)
+	if cached && !entry.matches(req) {
+		cached = false
+	}
+
+	if t.offline {
+		if !cached {
+			return nil, ErrNotCached
+		}
+		return entry.response(req, body), nil
+	}
+
+	if cached {
+		// Clone before adding validators; the caller owns req.
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lm := entry.Header.Get("Last-Modified"); lm != "" {
+			req.Header.Set("If-Modified-Since", lm)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		_ = resp.Body.Close()
+		for _, h := range []string{"ETag", "Last-Modified", "Date", "Cache-Control", "Expires"} {
+			if v := resp.Header.Get(h); v != "" {
+				entry.Header.Set(h, v)
+			}
+		}
+		entry.StoredAt = time.Now()
+		_ = t.store(key, entry, body)
+		cachedResp := entry.response(req, body)
+		// Cookies are never stored, but this revalidation may set some.
+		for _, c := range resp.Header.Values("Set-Cookie") {
+			cachedResp.Header.Add("Set-Cookie", c)
+		}
+		return cachedResp, nil
+	}
+
+	vary, ok := varyValues(req, resp)
+	if !ok || !cacheable(resp) {
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	_ = t.store(key, &cacheEntry{
+		URL:        key,
+		StatusCode: resp.StatusCode,
+		Header:     header,
+		StoredAt:   time.Now(),
+		Vary:       vary,
+	}, data)
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	return resp, nil
+}
+
+// cacheable reports whether a response should be written to the cache.
+// Server errors, rate limiting and partial content are always refetched.
+func cacheable(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode >= 500,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusNotModified,
+		resp.StatusCode == http.StatusPartialContent:
+		return false
+	}
+	return !strings.Contains(resp.Header.Get("Cache-Control"), "no-store")
+}
+
+// hasCredentials reports whether req carries anything that may make the
+// response personal.
+func (t *cachingTransport) hasCredentials(req *http.Request) bool {
+	if req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+		return true
+	}
+	for _, name := range t.private {
+		if req.Header.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// varyValues returns req's values for the headers resp varies on. It
+// reports false when resp must not be cached: "Vary: *", or variance on
+// credentials. User-Agent is ignored: without --user-agent every request
+// gets a random one, so honoring it would make every later run a miss.
+func varyValues(req *http.Request, resp *http.Response) (map[string]string, bool) {
+	var vary map[string]string
+	for _, v := range resp.Header.Values("Vary") {
+		for name := range strings.SplitSeq(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			switch name {
+			case "", "User-Agent":
+				continue
+			case "*", "Authorization", "Cookie":
+				return nil, false
+			}
+			if vary == nil {
+				vary = make(map[string]string)
+			}
+			vary[name] = req.Header.Get(name)
+		}
+	}
+	return vary, true
+}
+
+// matches reports whether req agrees with the request e was stored for on
+// every header the response varies on.
+func (e *cacheEntry) matches(req *http.Request) bool {
+	for name, v := range e.Vary {
+		if req.Header.Get(name) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *cachingTransport) load(key string) (*cacheEntry, []byte, bool) {
+	metaPath, bodyPath := t.paths(key)
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil || entry.URL != key {
+		return nil, nil, false
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	return &entry, body, true
+}
+
+// store writes body first and metadata last, each via rename, so a reader
+// never sees metadata pointing at a partial body.
+func (t *cachingTransport) store(key string, entry *cacheEntry, body []byte) error {
+	metaPath, bodyPath := t.paths(key)
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0o755); err != nil {
+		return err
+	}
+	meta, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(bodyPath, body); err != nil {
+		return err
+	}
+	return writeFileAtomic(metaPath, meta)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*~")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// response rebuilds an http.Response for req from a cache entry.
+func (e *cacheEntry) response(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
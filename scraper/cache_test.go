@@ -0,0 +1,185 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newCacheServer serves pages with an ETag, answering a matching
+// If-None-Match with 304. Paths under /private set a cookie, /vary varies
+// on Accept-Language, /ua also on User-Agent and /nostore forbids caching.
+func newCacheServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var notModified atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v1-` + r.URL.Path + `"`
+		switch r.URL.Path {
+		case "/private":
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "secret"})
+		case "/vary":
+			w.Header().Set("Vary", "Accept-Language")
+		case "/ua":
+			w.Header().Set("Vary", "User-Agent, Accept-Language")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprintf(w, "body of %s in %s", r.URL.Path, r.Header.Get("Accept-Language"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &notModified
+}
+
+func cacheGet(t *testing.T, rt http.RoundTripper, url string, header ...string) (string, http.Header, error) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body), resp.Header, nil
+}
+
+func TestCachingTransport(t *testing.T) {
+	srv, notModified := newCacheServer(t)
+	dir := t.TempDir()
+	online, err := newCachingTransport(dir, false, []string{"X-Api-Key"}, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline, err := newCachingTransport(dir, true, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Fill the cache.
+	for _, path := range []string{"/page", "/private", "/nostore"} {
+		if _, _, err := cacheGet(t, online, srv.URL+path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := cacheGet(t, online, srv.URL+"/vary", "Accept-Language", "en"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cacheGet(t, online, srv.URL+"/ua", "User-Agent", "Mozilla/5.0 (X11)", "Accept-Language", "en"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cacheGet(t, online, srv.URL+"/secret", "Authorization", "Bearer token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cacheGet(t, online, srv.URL+"/keyed", "X-Api-Key", "key-123"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Revalidation answers from disk.
+	body, _, err := cacheGet(t, online, srv.URL+"/page")
+	if err != nil || body != "body of /page in " {
+		t.Errorf("revalidated /page = %q, %v", body, err)
+	}
+	if notModified.Load() != 1 {
+		t.Errorf("server sent %d 304s, want 1", notModified.Load())
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		header []string
+		want   string // "" = not cached
+	}{
+		{name: "cached page", path: "/page", want: "body of /page in "},
+		{name: "cookie stripped", path: "/private", want: "body of /private in "},
+		{name: "vary match", path: "/vary", header: []string{"Accept-Language", "en"}, want: "body of /vary in en"},
+		{name: "vary mismatch", path: "/vary", header: []string{"Accept-Language", "fr"}},
+		// Random user agents would otherwise make every page a miss.
+		{name: "vary ignores user agent", path: "/ua", header: []string{"User-Agent", "Mozilla/5.0 (Macintosh)", "Accept-Language", "en"}, want: "body of /ua in en"},
+		{name: "no-store", path: "/nostore"},
+		{name: "credentialed", path: "/secret"},
+		{name: "private header", path: "/keyed"},
+		{name: "never fetched", path: "/missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, header, err := cacheGet(t, offline, srv.URL+tt.path, tt.header...)
+			if tt.want == "" {
+				if !errors.Is(err, ErrNotCached) {
+					t.Errorf("got %q, %v; want ErrNotCached", body, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+			if c := header.Get("Set-Cookie"); c != "" {
+				t.Errorf("cached response carries Set-Cookie %q", c)
+			}
+		})
+	}
+}
+
+func TestRunOffline(t *testing.T) {
+	srv := newSite(t, 3)
+	dir := t.TempDir()
+	opts := Options{
+		URLs:        []string{srv.URL + "/"},
+		Depth:       1,
+		Parallelism: 2,
+		CacheDir:    dir,
+	}
+	first, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	opts.Offline = true
+	opts.URLs = append(opts.URLs, srv.URL+"/never-fetched")
+	second, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := make(map[string]Result)
+	for _, r := range second {
+		pages[r.URL] = r
+	}
+	for _, r := range first {
+		got, ok := pages[r.URL]
+		if !ok {
+			t.Errorf("%s missing from the offline run", r.URL)
+			continue
+		}
+		if got.Err != nil || got.Markdown != r.Markdown {
+			t.Errorf("%s offline = %q, %v; want %q", r.URL, got.Markdown, got.Err, r.Markdown)
+		}
+	}
+	if r, ok := pages[srv.URL+"/never-fetched"]; !ok || !errors.Is(r.Err, ErrNotCached) {
+		t.Errorf("uncached page: got %+v, want an ErrNotCached result", r)
+	}
+	if len(second) != len(first)+1 {
+		t.Errorf("offline run returned %d results, want %d", len(second), len(first)+1)
+	}
+}
@@ -0,0 +1,113 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only identify where a visitor
+// came from. They never change what a page serves.
+var trackingParams = map[string]bool{
+	"gclid":   true,
+	"gclsrc":  true,
+	"dclid":   true,
+	"fbclid":  true,
+	"msclkid": true,
+	"yclid":   true,
+	"twclid":  true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_ga":     true,
+	"_gl":     true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"mkt_tok": true,
+	"ref_src": true,
+}
+
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return trackingParams[name] || strings.HasPrefix(name, "utm_")
+}
+
+// CanonicalURL normalizes raw so different spellings of the same page
+// compare equal: scheme and host are lowercased, default ports, fragments
+// and tracking parameters are dropped, and a trailing index.html becomes
+// the directory it is served for. A trailing slash is kept; pageKey
+// ignores it when matching duplicates. URLs that do not parse are returned
+// unchanged.
+func CanonicalURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.Path == "" {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+	for _, index := range []string{"index.html", "index.htm"} {
+		if dir, ok := strings.CutSuffix(u.Path, "/"+index); ok {
+			u.Path = dir + "/"
+			u.RawPath = ""
+			break
+		}
+	}
+
+	if u.RawQuery != "" {
+		var kept []string
+		for param := range strings.SplitSeq(u.RawQuery, "&") {
+			name, _, _ := strings.Cut(param, "=")
+			if name, err := url.QueryUnescape(name); err == nil && isTrackingParam(name) {
+				continue
+			}
+			if param != "" {
+				kept = append(kept, param)
+			}
+		}
+		u.RawQuery = strings.Join(kept, "&")
+	}
+	u.ForceQuery = false
+	return u.String()
+}
+
+// pageKey returns the key under which URLs are compared as the same page:
+// the canonical URL without the trailing slash of a non-root path, since
+// servers almost always answer /docs and /docs/ with the same page.
+// CanonicalURL keeps the slash because it also names what is fetched, and
+// relative links resolve differently against the two.
+func pageKey(raw string) string {
+	canon := CanonicalURL(raw)
+	u, err := url.Parse(canon)
+	if err != nil || u.Host == "" || u.Path == "/" {
+		return canon
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	return u.String()
+}
+
+// sameHost reports whether two URLs name the same host.
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Host, ub.Host)
+}
@@ -0,0 +1,48 @@
+package scraper
+
+import "testing"
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://example.com", "https://example.com/"},
+		{"HTTPS://Example.COM/Docs", "https://example.com/Docs"},
+		{"http://example.com:80/a", "http://example.com/a"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"https://example.com:8443/a", "https://example.com:8443/a"},
+		{"https://example.com/a#section", "https://example.com/a"},
+		{"https://example.com/guide/index.html", "https://example.com/guide/"},
+		{"https://example.com/index.htm", "https://example.com/"},
+		{"https://example.com/a?utm_source=x&id=3&fbclid=y", "https://example.com/a?id=3"},
+		{"https://example.com/a?UTM_Medium=x", "https://example.com/a"},
+		{"https://example.com/a?", "https://example.com/a"},
+		{"https://example.com/a?b=1&&c=2", "https://example.com/a?b=1&c=2"},
+		{"http://[::1]:8080/x", "http://[::1]:8080/x"},
+		{"/relative/path", "/relative/path"},
+		{"mailto:someone@example.com", "mailto:someone@example.com"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalURL(tt.in); got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPageKey(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://example.com/docs/", "https://example.com/docs"},
+		{"https://Example.com/docs", "https://example.com/docs"},
+		{"https://example.com/docs/index.html?utm_source=x", "https://example.com/docs"},
+		{"https://example.com/docs/?v=2", "https://example.com/docs?v=2"},
+		{"https://example.com/", "https://example.com/"},
+		{"https://example.com", "https://example.com/"},
+	}
+	for _, tt := range tests {
+		if got := pageKey(tt.in); got != tt.want {
+			t.Errorf("pageKey(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package scraper
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+// BrokenLink is a link on a crawled page whose target could not be
+// fetched, or that points at an anchor its target page does not have.
+type BrokenLink struct {
+	URL      string // as linked, resolved against the page
+	Source   string // page the link is on
+	Text     string // link text
+	Status   int    // HTTP status of the target, 0 when there was no response
+	Err      error  // why the request failed, if it did
+	Fragment string // the missing anchor, when the page itself is fine
+}
+
+// LinkReport is the outcome of a link check.
+type LinkReport struct {
+	Pages   int // pages crawled
+	Links   int // distinct link targets verified
+	Skipped int // targets left unchecked: external, or disallowed by robots.txt
+	Broken  []BrokenLink
+}
+
+// LinkChecker crawls a site with Run and verifies every link found on its
+// pages. Targets the crawl fetched are judged by that response; the rest
+// are requested afterwards with HEAD, falling back to GET.
+type LinkChecker struct {
+	External bool // also verify links to hosts outside the crawl
+
+	opts  Options
+	hooks *crawlHooks
+}
+
+// NewLinkChecker returns a checker that verifies links to other hosts
+// only when external is set.
+func NewLinkChecker(external bool) *LinkChecker {
+	return &LinkChecker{External: external}
+}
+
+// Instrument returns opts set up to record the pages and links Check
+// needs. Run the crawl with the returned options, then call Check.
+func (lc *LinkChecker) Instrument(opts Options) Options {
+	lc.hooks = &crawlHooks{
+		pages:   make(map[string]pageStatus),
+		anchors: make(map[string]map[string]bool),
+		seen:    make(map[foundLink]bool),
+	}
+	opts.hooks = lc.hooks
+	takeURLCredentials(&opts)
+	lc.opts = opts
+	return opts
+}
+
+// Check verifies the links recorded during the crawl and reports the
+// broken ones, sorted by target.
+func (lc *LinkChecker) Check(ctx context.Context) (*LinkReport, error) {
+	h := lc.hooks
+	if h == nil {
+		return nil, fmt.Errorf("link checker was not attached to a crawl")
+	}
+	opts := lc.opts
+	limiter := newHostLimiter(opts.HostParallelism, opts.Delay, opts.RandomDelay)
+	sess, err := newSession(opts)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := buildTransport(opts, limiter, sess)
+	if err != nil {
+		return nil, err
+	}
+
+	internal := extractDomains(opts.URLs)
+	isInternal := func(u *url.URL) bool {
+		return slices.Contains(internal, u.Hostname())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Work out which targets the crawl did not already fetch.
+	report := &LinkReport{Pages: h.crawled}
+	pending := make(map[string]bool)
+	skipped := make(map[string]bool)
+	for _, l := range h.links {
+		u, ok := httpURL(l.to)
+		if !ok {
+			continue
+		}
+		target := CanonicalURL(l.to)
+		if _, ok := h.pages[target]; ok {
+			continue
+		}
+		if !lc.External && !isInternal(u) {
+			skipped[target] = true
+			continue
+		}
+		pending[target] = true
+	}
+
+	var robots *robotsCache
+	if !opts.IgnoreRobots {
+		robots = newRobotsCache(transport, 15*time.Second, opts.UserAgent)
+	}
+	client := &http.Client{Transport: transport, Timeout: 15 * time.Second}
+	backoff := cmp.Or(opts.RetryBackoff, defaultRetryBackoff)
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, max(1, opts.Parallelism))
+	)
+	for target := range pending {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			st := probeLink(ctx, client, robots, limiter, target, opts.Retries, backoff)
+			mu.Lock()
+			h.pages[target] = st
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	checked := make(map[string]bool)
+	for _, l := range h.links {
+		if _, ok := httpURL(l.to); !ok {
+			continue
+		}
+		target := CanonicalURL(l.to)
+		st, ok := h.pages[target]
+		if !ok || st.skipped {
+			skipped[target] = true
+			continue
+		}
+		checked[target] = true
+
+		if st.err != nil || st.status >= 400 {
+			report.Broken = append(report.Broken, BrokenLink{
+				URL: l.to, Source: l.from, Text: l.text, Status: st.status, Err: st.err,
+			})
+			continue
+		}
+		frag := linkFragment(l.to)
+		if frag == "" {
+			continue
+		}
+		// Only HTML pages the crawl parsed have known anchors.
+		if ids, ok := h.anchors[cmp.Or(st.final, target)]; ok && !ids[frag] {
+			report.Broken = append(report.Broken, BrokenLink{
+				URL: l.to, Source: l.from, Text: l.text, Status: st.status, Fragment: frag,
+			})
+		}
+	}
+	report.Links = len(checked)
+	report.Skipped = len(skipped)
+
+	slices.SortFunc(report.Broken, func(a, b BrokenLink) int {
+		return cmp.Or(strings.Compare(a.URL, b.URL), strings.Compare(a.Source, b.Source), strings.Compare(a.Text, b.Text))
+	})
+	return report, nil
+}
+
+// httpURL parses raw, reporting whether it is an http(s) URL worth
+// checking rather than mailto:, javascript: and the like.
+func httpURL(raw string) (*url.URL, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, false
+	}
+	return u, true
+}
+
+// linkFragment returns the anchor a link points at, ignoring the empty
+// and "top" fragments browsers always resolve, and text fragments.
+func linkFragment(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	frag := u.Fragment
+	if frag == "" || strings.EqualFold(frag, "top") || strings.HasPrefix(frag, ":~:") {
+		return ""
+	}
+	return frag
+}
+
+// probeLink requests target with HEAD, retrying transient failures.
+// Servers that reject HEAD get a GET before the link counts as broken.
+func probeLink(ctx context.Context, client *http.Client, robots *robotsCache, limiter *hostLimiter, target string, retries int, backoff time.Duration) pageStatus {
+	u, err := url.Parse(target)
+	if err != nil {
+		return pageStatus{err: err}
+	}
+	var crawlDelay time.Duration
+	if robots != nil {
+		if !robots.allowed(ctx, u) {
+			return pageStatus{skipped: true}
+		}
+		crawlDelay = robots.crawlDelay(ctx, u)
+	}
+	for attempt := 0; ; attempt++ {
+		release, ok := limiter.acquire(ctx, u.Host, crawlDelay)
+		if !ok {
+			return pageStatus{err: ctx.Err()}
+		}
+		st, headers := requestLink(ctx, client, http.MethodHead, target)
+		if st.status >= 400 {
+			st, headers = requestLink(ctx, client, http.MethodGet, target)
+		}
+		release()
+		if attempt < retries && isTransient(st.status, st.err) {
+			if !sleepCtx(ctx, retryDelay(backoff, attempt+1, headers)) {
+				return st
+			}
+			continue
+		}
+		return st
+	}
+}
+
+func requestLink(ctx context.Context, client *http.Client, method, target string) (pageStatus, *http.Header) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return pageStatus{err: err}, nil
+	}
+	req.Header.Set("User-Agent", robotsAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return pageStatus{err: classifyTLS(err)}, nil
+	}
+	defer resp.Body.Close()
+	// A little of the body lets the connection be reused.
+	_, _ = io.CopyN(io.Discard, resp.Body, 4<<10)
+	return pageStatus{status: resp.StatusCode, final: CanonicalURL(resp.Request.URL.String())}, &resp.Header
+}
+
+// crawlHooks records what a crawl saw for a LinkChecker. Run calls its
+// methods from colly's workers; a nil *crawlHooks ignores every call.
+type crawlHooks struct {
+	mu      sync.Mutex
+	crawled int
+	pages   map[string]pageStatus      // canonical URL → outcome of fetching it
+	anchors map[string]map[string]bool // canonical page URL → element ids and anchor names
+	links   []foundLink
+	seen    map[foundLink]bool
+}
+
+type pageStatus struct {
+	status  int
+	err     error
+	final   string // canonical URL after redirects
+	skipped bool   // never requested: disallowed by robots.txt
+}
+
+type foundLink struct {
+	from, to, text string
+}
+
+// page records the outcome of fetching requested, which ended at final.
+func (h *crawlHooks) page(requested, final string, status int, err error) {
+	if h == nil {
+		return
+	}
+	st := pageStatus{status: status, err: err, final: CanonicalURL(final)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.crawled++
+	h.pages[CanonicalURL(requested)] = st
+	if _, ok := h.pages[st.final]; !ok {
+		h.pages[st.final] = st
+	}
+}
+
+func (h *crawlHooks) link(from, to, text string) {
+	l := foundLink{from: from, to: to, text: text}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.seen[l] {
+		h.seen[l] = true
+		h.links = append(h.links, l)
+	}
+}
+
+// htmlLink records an <a href> element.
+func (h *crawlHooks) htmlLink(e *colly.HTMLElement) {
+	if h == nil {
+		return
+	}
+	href := strings.TrimSpace(e.Attr("href"))
+	page := e.Request.URL.String()
+	var to string
+	if strings.HasPrefix(href, "#") {
+		to = strings.SplitN(page, "#", 2)[0] + href
+	} else {
+		to = e.Request.AbsoluteURL(href)
+	}
+	if to == "" {
+		return
+	}
+	text := strings.Join(strings.Fields(e.Text), " ")
+	if text == "" {
+		text = cmp.Or(e.Attr("aria-label"), e.Attr("title"), e.ChildAttr("img", "alt"))
+	}
+	h.link(page, to, text)
+}
+
+// markdownLinks records the links of a markdown page.
+func (h *crawlHooks) markdownLinks(md, pageURL string) {
+	if h == nil {
+		return
+	}
+	for _, l := range markdownLinks(md, pageURL) {
+		h.link(pageURL, l.url, l.text)
+	}
+}
+
+// htmlAnchors records the fragment targets an HTML page defines.
+func (h *crawlHooks) htmlAnchors(pageURL string, doc *goquery.Document) {
+	if h == nil {
+		return
+	}
+	ids := make(map[string]bool)
+	doc.Find("[id]").Each(func(_ int, s *goquery.Selection) {
+		ids[s.AttrOr("id", "")] = true
+	})
+	doc.Find("a[name]").Each(func(_ int, s *goquery.Selection) {
+		ids[s.AttrOr("name", "")] = true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.anchors[CanonicalURL(pageURL)] = ids
+}
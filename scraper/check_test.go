@@ -0,0 +1,130 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLinkChecker(t *testing.T) {
+	// The external host rejects HEAD, so /fine is only found on GET.
+	ext := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/fine" && r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		case r.URL.Path == "/fine":
+			fmt.Fprint(w, "ok")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ext.Close()
+	// Another hostname for the same loopback address makes it external.
+	extURL := strings.Replace(ext.URL, "127.0.0.1", "localhost", 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprintf(w, `<html><body><h1 id="intro">Home</h1>
+<a href="/ok">ok</a>
+<a href="/ok#exists">exists</a>
+<a href="/ok#missing">missing anchor</a>
+<a href="#intro">self</a>
+<a href="#nowhere">self missing</a>
+<a href="/ok#top">top</a>
+<a href="/gone">gone</a>
+<a href="mailto:someone@example.com">mail</a>
+<a href="%[1]s/fine">external fine</a>
+<a href="%[1]s/dead">external dead</a>
+</body></html>`, extURL)
+		case "/ok":
+			fmt.Fprint(w, `<html><body><h2 id="exists">Here</h2><a name="legacy"></a><p>Fine.</p></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		external bool
+		want     []string // URL status fragment
+		links    int
+		skipped  int
+	}{
+		{
+			name: "internal",
+			want: []string{
+				srv.URL + "/#nowhere 200 nowhere",
+				srv.URL + "/gone 404 ",
+				srv.URL + "/ok#missing 200 missing",
+			},
+			links:   3,
+			skipped: 2,
+		},
+		{
+			name:     "external",
+			external: true,
+			want: []string{
+				srv.URL + "/#nowhere 200 nowhere",
+				srv.URL + "/gone 404 ",
+				srv.URL + "/ok#missing 200 missing",
+				extURL + "/dead 404 ",
+			},
+			links: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lc := NewLinkChecker(tt.external)
+			opts := lc.Instrument(Options{URLs: []string{srv.URL + "/"}, Depth: 1, Parallelism: 2})
+			if _, err := Run(context.Background(), opts); err != nil {
+				t.Fatal(err)
+			}
+			report, err := lc.Check(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, b := range report.Broken {
+				if b.Source != srv.URL+"/" {
+					t.Errorf("%s: source %q, want the home page", b.URL, b.Source)
+				}
+				got = append(got, fmt.Sprintf("%s %d %s", b.URL, b.Status, b.Fragment))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("broken links:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if report.Links != tt.links || report.Skipped != tt.skipped {
+				t.Errorf("links %d, skipped %d; want %d, %d", report.Links, report.Skipped, tt.links, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestLinkFragment(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"https://example.com/a", ""},
+		{"https://example.com/a#", ""},
+		{"https://example.com/a#Top", ""},
+		{"https://example.com/a#:~:text=hello", ""},
+		{"https://example.com/a#install", "install"},
+	}
+	for _, tt := range tests {
+		if got := linkFragment(tt.url); got != tt.want {
+			t.Errorf("linkFragment(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckWithoutCrawl(t *testing.T) {
+	if _, err := NewLinkChecker(false).Check(context.Background()); err == nil {
+		t.Error("Check before Instrument: want an error")
+	}
+}
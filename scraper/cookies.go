@@ -0,0 +1,234 @@
+package scraper
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// cookieJar is an http.CookieJar that can also be read from and written to
+// Netscape cookies.txt files, the format curl, wget and browser extensions
+// use. It is safe for concurrent use.
+type cookieJar struct {
+	mu      sync.Mutex
+	entries map[string]*jarCookie // domain;path;name → cookie
+}
+
+type jarCookie struct {
+	name, value  string
+	domain, path string
+	hostOnly     bool      // sent to domain only, not its subdomains
+	secure       bool      // sent over https only
+	httpOnly     bool      // kept for export; scraped never runs scripts
+	expires      time.Time // zero for session cookies
+}
+
+func newCookieJar() *cookieJar {
+	return &cookieJar{entries: make(map[string]*jarCookie)}
+}
+
+func (c *jarCookie) key() string {
+	return c.domain + ";" + c.path + ";" + c.name
+}
+
+// matches reports whether c should be sent with a request to u at now.
+func (c *jarCookie) matches(u *url.URL, now time.Time) bool {
+	if !c.expires.IsZero() && !c.expires.After(now) {
+		return false
+	}
+	if c.secure && u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if c.hostOnly && host != c.domain || !c.hostOnly && !domainMatch(host, c.domain) {
+		return false
+	}
+	return pathMatch(cmp.Or(u.EscapedPath(), "/"), c.path)
+}
+
+// SetCookies stores the cookies a response from u set, following the
+// domain and path rules browsers apply: a server can only set cookies for
+// its own domain and never for a public suffix such as "co.uk".
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := strings.ToLower(u.Hostname())
+	now := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, hc := range cookies {
+		c := &jarCookie{
+			name:     hc.Name,
+			value:    hc.Value,
+			domain:   host,
+			hostOnly: true,
+			path:     hc.Path,
+			secure:   hc.Secure,
+			httpOnly: hc.HttpOnly,
+		}
+		if d := strings.ToLower(strings.TrimPrefix(hc.Domain, ".")); d != "" && d != host {
+			if !domainMatch(host, d) || isPublicSuffix(d) {
+				continue
+			}
+			c.domain, c.hostOnly = d, false
+		} else if d != "" {
+			c.hostOnly = false
+		}
+		if !strings.HasPrefix(c.path, "/") {
+			c.path = defaultCookiePath(u.EscapedPath())
+		}
+		switch {
+		case hc.MaxAge < 0:
+			c.expires = now
+		case hc.MaxAge > 0:
+			c.expires = now.Add(time.Duration(hc.MaxAge) * time.Second)
+		default:
+			c.expires = hc.Expires
+		}
+		if !c.expires.IsZero() && !c.expires.After(now) {
+			delete(j.entries, c.key())
+			continue
+		}
+		j.entries[c.key()] = c
+	}
+}
+
+// Cookies returns the cookies to send with a request to u, longest path
+// first.
+func (j *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	now := time.Now()
+	j.mu.Lock()
+	var matched []*jarCookie
+	for _, c := range j.entries {
+		if c.matches(u, now) {
+			matched = append(matched, c)
+		}
+	}
+	j.mu.Unlock()
+
+	slices.SortFunc(matched, func(a, b *jarCookie) int {
+		return cmp.Or(cmp.Compare(len(b.path), len(a.path)), strings.Compare(a.name, b.name))
+	})
+	out := make([]*http.Cookie, len(matched))
+	for i, c := range matched {
+		out[i] = &http.Cookie{Name: c.name, Value: c.value}
+	}
+	return out
+}
+
+// add stores a cookie given on the command line.
+func (j *cookieJar) add(c *jarCookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries[c.key()] = c
+}
+
+// load reads cookies in Netscape cookies.txt format. Expired cookies are
+// dropped.
+func (j *cookieJar) load(r io.Reader) error {
+	now := time.Now()
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		httpOnly := false
+		if after, ok := strings.CutPrefix(line, "#HttpOnly_"); ok {
+			line, httpOnly = after, true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.Split(line, "\t")
+		if len(f) != 7 {
+			return fmt.Errorf("line %d: want 7 tab-separated fields, got %d", n, len(f))
+		}
+		expiry, err := strconv.ParseInt(f[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid expiry %q", n, f[4])
+		}
+		c := &jarCookie{
+			name:     f[5],
+			value:    f[6],
+			domain:   strings.ToLower(strings.TrimPrefix(f[0], ".")),
+			hostOnly: !strings.EqualFold(f[1], "TRUE"),
+			path:     cmp.Or(f[2], "/"),
+			secure:   strings.EqualFold(f[3], "TRUE"),
+			httpOnly: httpOnly,
+		}
+		if expiry > 0 {
+			c.expires = time.Unix(expiry, 0)
+			if !c.expires.After(now) {
+				continue
+			}
+		}
+		j.entries[c.key()] = c
+	}
+	return sc.Err()
+}
+
+// save writes every unexpired cookie in Netscape cookies.txt format.
+func (j *cookieJar) save(w io.Writer) error {
+	now := time.Now()
+	j.mu.Lock()
+	var all []*jarCookie
+	for _, c := range j.entries {
+		if c.expires.IsZero() || c.expires.After(now) {
+			all = append(all, c)
+		}
+	}
+	j.mu.Unlock()
+	slices.SortFunc(all, func(a, b *jarCookie) int { return strings.Compare(a.key(), b.key()) })
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("# Netscape HTTP Cookie File\n# Written by scraped. Holds credentials; keep it private.\n\n")
+	for _, c := range all {
+		domain, sub := c.domain, "FALSE"
+		if !c.hostOnly {
+			domain, sub = "."+c.domain, "TRUE"
+		}
+		if c.httpOnly {
+			domain = "#HttpOnly_" + domain
+		}
+		var expiry int64
+		if !c.expires.IsZero() {
+			expiry = c.expires.Unix()
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, sub, c.path, strings.ToUpper(strconv.FormatBool(c.secure)), expiry, c.name, c.value)
+	}
+	return bw.Flush()
+}
+
+// domainMatch reports whether host is domain or one of its subdomains.
+func domainMatch(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+func pathMatch(reqPath, cookiePath string) bool {
+	if !strings.HasPrefix(reqPath, cookiePath) {
+		return false
+	}
+	return len(reqPath) == len(cookiePath) || strings.HasSuffix(cookiePath, "/") || reqPath[len(cookiePath)] == '/'
+}
+
+// defaultCookiePath is the path a cookie without a Path attribute gets:
+// the directory of the request path.
+func defaultCookiePath(reqPath string) string {
+	i := strings.LastIndex(reqPath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return reqPath[:i]
+}
+
+func isPublicSuffix(domain string) bool {
+	ps, _ := publicsuffix.PublicSuffix(domain)
+	return ps == domain
+}
@@ -0,0 +1,96 @@
+package scraper
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCookieJarLoad(t *testing.T) {
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+	file := strings.Join([]string{
+		"# Netscape HTTP Cookie File",
+		"",
+		"example.com\tFALSE\t/\tFALSE\t0\thost\tonly",
+		".example.com\tTRUE\t/\tTRUE\t" + strconv.FormatInt(future, 10) + "\tsecure\tyes",
+		"#HttpOnly_.example.com\tTRUE\t/docs\tFALSE\t0\tdocs\tpath",
+		".example.com\tTRUE\t/\tFALSE\t" + strconv.FormatInt(past, 10) + "\texpired\tgone",
+	}, "\n")
+	j := newCookieJar()
+	if err := j.load(strings.NewReader(file)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://example.com/", "host=only"},
+		{"https://example.com/", "host=only; secure=yes"},
+		{"https://www.example.com/", "secure=yes"},
+		{"http://example.com/docs/a", "docs=path; host=only"},
+		{"http://example.com/docsx", "host=only"},
+		{"http://other.example/", ""},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := cookieString(j.Cookies(u)); got != tt.want {
+			t.Errorf("Cookies(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCookieJarLoadErrors(t *testing.T) {
+	tests := []struct {
+		file, want string
+	}{
+		{"example.com\tFALSE\t/\tFALSE\t0\tname", "line 1: want 7 tab-separated fields, got 6"},
+		{"# comment\nexample.com\tFALSE\t/\tFALSE\tsoon\tname\tvalue", `line 2: invalid expiry "soon"`},
+	}
+	for _, tt := range tests {
+		err := newCookieJar().load(strings.NewReader(tt.file))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("load(%q) error = %v, want %q", tt.file, err, tt.want)
+		}
+	}
+}
+
+func TestCookieJarRoundTrip(t *testing.T) {
+	j := newCookieJar()
+	u, _ := url.Parse("https://shop.example.com/cart/view")
+	j.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "abc", HttpOnly: true, Secure: true},
+		{Name: "site", Value: "wide", Domain: "example.com", Path: "/", MaxAge: 3600},
+		// A server cannot set cookies for a public suffix.
+		{Name: "evil", Value: "x", Domain: "com"},
+	})
+
+	var buf bytes.Buffer
+	if err := j.save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := newCookieJar()
+	if err := loaded.load(&buf); err != nil {
+		t.Fatalf("reloading saved jar: %v\n%s", err, buf.String())
+	}
+	if got, want := cookieString(loaded.Cookies(u)), "session=abc; site=wide"; got != want {
+		t.Errorf("Cookies(%s) = %q, want %q", u, got, want)
+	}
+	other, _ := url.Parse("https://www.example.com/")
+	if got, want := cookieString(loaded.Cookies(other)), "site=wide"; got != want {
+		t.Errorf("Cookies(%s) = %q, want %q", other, got, want)
+	}
+}
+
+func cookieString(cookies []*http.Cookie) string {
+	var parts []string
+	for _, c := range cookies {
+		parts = append(parts, c.String())
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,206 @@
+package scraper
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Content dedup modes for Options.Dedupe. URL canonicalization applies in
+// every mode.
+const (
+	DedupeExact   = "exact"   // identical markdown after whitespace normalization
+	DedupeSimilar = "similar" // simhash within simhashDistance bits
+)
+
+const (
+	// simhashDistance is the largest Hamming distance between two page
+	// fingerprints still treated as the same page.
+	simhashDistance = 3
+	// simhashBands splits a fingerprint for lookup: two fingerprints within
+	// simhashDistance bits share at least one band exactly.
+	simhashBands = simhashDistance + 1
+	// shingleWords is the length of the word sequences fingerprinted.
+	shingleWords = 3
+)
+
+// dedupIndex decides whether a finished page duplicates one already kept.
+// Pages match on their canonical URL, redirect target or rel=canonical
+// link, and optionally on content. It is safe for concurrent use.
+type dedupIndex struct {
+	mode   string
+	mu     sync.Mutex
+	urls   map[string]string // canonical URL → URL of the kept result
+	hashes map[[sha256.Size]byte]string
+	bands  [simhashBands]map[uint16][]simEntry
+}
+
+type simEntry struct {
+	hash uint64
+	url  string
+}
+
+func newDedupIndex(mode string) (*dedupIndex, error) {
+	switch mode {
+	case "", DedupeExact, DedupeSimilar:
+	default:
+		return nil, fmt.Errorf("invalid dedupe mode %q (want %q or %q)", mode, DedupeExact, DedupeSimilar)
+	}
+	d := &dedupIndex{
+		mode:   mode,
+		urls:   make(map[string]string),
+		hashes: make(map[[sha256.Size]byte]string),
+	}
+	for i := range d.bands {
+		d.bands[i] = make(map[uint16][]simEntry)
+	}
+	return d, nil
+}
+
+// urlKeys returns the page keys of the URLs r is known by. A rel=canonical
+// link is honored only on the page's own host, so syndicated copies are
+// kept.
+func urlKeys(r Result) []string {
+	keys := []string{pageKey(r.URL)}
+	if r.Meta.FinalURL != "" {
+		keys = append(keys, pageKey(r.Meta.FinalURL))
+	}
+	if c := r.Meta.Canonical; c != "" && sameHost(c, cmp.Or(r.Meta.FinalURL, r.URL)) {
+		keys = append(keys, pageKey(c))
+	}
+	for _, a := range r.Aliases {
+		keys = append(keys, pageKey(a))
+	}
+	return keys
+}
+
+// check returns the URL of the kept result r duplicates, or registers r as
+// kept and reports false. Failed pages are never duplicates, and pages
+// without text are compared by URL only.
+func (d *dedupIndex) check(r Result) (string, bool) {
+	if r.Err != nil {
+		return "", false
+	}
+	keys := urlKeys(r)
+	mode := d.mode
+	text := strings.Join(strings.Fields(r.Markdown), " ")
+	if text == "" {
+		mode = ""
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	kept := ""
+	for _, k := range keys {
+		if u, ok := d.urls[k]; ok {
+			kept = u
+			break
+		}
+	}
+
+	var (
+		sum  [sha256.Size]byte
+		hash uint64
+	)
+	switch mode {
+	case DedupeExact:
+		sum = sha256.Sum256([]byte(text))
+		if u, ok := d.hashes[sum]; ok && kept == "" {
+			kept = u
+		}
+	case DedupeSimilar:
+		hash = simhash(text)
+		if u, ok := d.similar(hash); ok && kept == "" {
+			kept = u
+		}
+	}
+
+	// Later variants of a duplicate resolve straight to the kept page.
+	owner := r.URL
+	if kept != "" {
+		owner = kept
+	}
+	for _, k := range keys {
+		if _, ok := d.urls[k]; !ok {
+			d.urls[k] = owner
+		}
+	}
+	if kept != "" {
+		return kept, true
+	}
+
+	switch mode {
+	case DedupeExact:
+		d.hashes[sum] = r.URL
+	case DedupeSimilar:
+		for i, band := range simhashBandsOf(hash) {
+			d.bands[i][band] = append(d.bands[i][band], simEntry{hash: hash, url: r.URL})
+		}
+	}
+	return "", false
+}
+
+// similar finds a kept page whose fingerprint is within simhashDistance
+// bits of hash. The caller holds d.mu.
+func (d *dedupIndex) similar(hash uint64) (string, bool) {
+	for i, band := range simhashBandsOf(hash) {
+		for _, e := range d.bands[i][band] {
+			if bits.OnesCount64(e.hash^hash) <= simhashDistance {
+				return e.url, true
+			}
+		}
+	}
+	return "", false
+}
+
+func simhashBandsOf(hash uint64) [simhashBands]uint16 {
+	var out [simhashBands]uint16
+	for i := range out {
+		out[i] = uint16(hash >> (16 * i))
+	}
+	return out
+}
+
+// simhash fingerprints text from its overlapping word shingles, so pages
+// that differ in a few words get fingerprints a few bits apart.
+func simhash(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+	n := max(1, len(words)-shingleWords+1)
+
+	var weights [64]int
+	h := fnv.New64a()
+	for i := range n {
+		h.Reset()
+		for _, w := range words[i:min(i+shingleWords, len(words))] {
+			h.Write([]byte(w))
+			h.Write([]byte{0})
+		}
+		v := h.Sum64()
+		for b := range weights {
+			if v&(1<<b) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var out uint64
+	for b, w := range weights {
+		if w > 0 {
+			out |= 1 << b
+		}
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// longText returns a few hundred distinct words, with word i replaced by
+// swap when i >= 0.
+func longText(i int, swap string) string {
+	words := make([]string, 300)
+	for n := range words {
+		words[n] = fmt.Sprintf("word%d", n)
+	}
+	if i >= 0 {
+		words[i] = swap
+	}
+	return strings.Join(words, " ")
+}
+
+func TestDedupIndex(t *testing.T) {
+	type page struct {
+		url, final, canonical, md string
+		wantOf                    string // "" = kept
+	}
+	tests := []struct {
+		name  string
+		mode  string
+		pages []page
+	}{
+		{
+			name: "canonical URL variants",
+			pages: []page{
+				{url: "https://example.com/docs/", md: "a"},
+				{url: "https://Example.com/docs/index.html?utm_source=x#top", md: "b", wantOf: "https://example.com/docs/"},
+				{url: "https://example.com/other", md: "a"},
+				{url: "https://example.com/docs", md: "c", wantOf: "https://example.com/docs/"},
+			},
+		},
+		{
+			name: "redirect target and rel=canonical",
+			pages: []page{
+				{url: "https://example.com/new", md: "a"},
+				{url: "https://example.com/old", final: "https://example.com/new", md: "a", wantOf: "https://example.com/new"},
+				{url: "https://example.com/print", canonical: "https://example.com/new", md: "a", wantOf: "https://example.com/new"},
+				// rel=canonical pointing at another host is ignored.
+				{url: "https://mirror.example/new", canonical: "https://example.com/new", md: "a"},
+			},
+		},
+		{
+			name: "exact content",
+			mode: DedupeExact,
+			pages: []page{
+				{url: "https://example.com/a", md: "Same  text\nhere."},
+				{url: "https://example.com/b", md: "Same text here.", wantOf: "https://example.com/a"},
+				{url: "https://example.com/c", md: "Other text."},
+				// Empty pages are compared by URL only.
+				{url: "https://example.com/d", md: ""},
+				{url: "https://example.com/e", md: " "},
+			},
+		},
+		{
+			name: "similar content",
+			mode: DedupeSimilar,
+			pages: []page{
+				{url: "https://example.com/a", md: longText(-1, "")},
+				{url: "https://example.com/b", md: longText(150, "changed"), wantOf: "https://example.com/a"},
+				{url: "https://example.com/c", md: strings.ToUpper(longText(-1, "")) + " different words entirely " + strings.Repeat("other ", 300)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDedupIndex(tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range tt.pages {
+				r := Result{URL: p.url, Markdown: p.md, Meta: Metadata{FinalURL: p.final, Canonical: p.canonical}}
+				of, dup := d.check(r)
+				if dup != (p.wantOf != "") || of != p.wantOf {
+					t.Errorf("check(%s) = %q, %v; want %q", p.url, of, dup, p.wantOf)
+				}
+			}
+		})
+	}
+}
+
+func TestNewDedupIndexInvalidMode(t *testing.T) {
+	if _, err := newDedupIndex("fuzzy"); err == nil {
+		t.Error(`newDedupIndex("fuzzy"): want an error`)
+	}
+}
@@ -0,0 +1,87 @@
+package scraper
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// urlMatcher matches a full URL string.
+type urlMatcher interface {
+	Match(s string) bool
+}
+
+type regexpMatcher struct{ re *regexp.Regexp }
+
+func (m regexpMatcher) Match(s string) bool { return m.re.MatchString(s) }
+
+// compilePattern compiles an --include/--exclude pattern. Patterns are
+// regular expressions matched anywhere in the URL; a "glob:" prefix selects
+// glob syntax matched against the whole URL, where * also spans slashes.
+func compilePattern(p string) (urlMatcher, error) {
+	if g, ok := strings.CutPrefix(p, "glob:"); ok {
+		m, err := glob.Compile(g)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", g, err)
+		}
+		return m, nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+	}
+	return regexpMatcher{re}, nil
+}
+
+// urlFilter scopes which discovered links a crawl follows.
+type urlFilter struct {
+	include    []urlMatcher
+	exclude    []urlMatcher
+	pathPrefix string
+}
+
+func newURLFilter(include, exclude []string, pathPrefix string) (*urlFilter, error) {
+	f := &urlFilter{pathPrefix: pathPrefix}
+	for _, p := range include {
+		m, err := compilePattern(p)
+		if err != nil {
+			return nil, fmt.Errorf("--include: %w", err)
+		}
+		f.include = append(f.include, m)
+	}
+	for _, p := range exclude {
+		m, err := compilePattern(p)
+		if err != nil {
+			return nil, fmt.Errorf("--exclude: %w", err)
+		}
+		f.exclude = append(f.exclude, m)
+	}
+	return f, nil
+}
+
+// allow reports whether link passes the path prefix, matches at least one
+// include pattern (when any are set), and matches no exclude pattern.
+func (f *urlFilter) allow(link string) bool {
+	if f.pathPrefix != "" {
+		u, err := url.Parse(link)
+		if err != nil || !strings.HasPrefix(u.Path, f.pathPrefix) {
+			return false
+		}
+	}
+	if len(f.include) > 0 && !matchAny(f.include, link) {
+		return false
+	}
+	return !matchAny(f.exclude, link)
+}
+
+func matchAny(ms []urlMatcher, s string) bool {
+	for _, m := range ms {
+		if m.Match(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestURLFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		include    []string
+		exclude    []string
+		pathPrefix string
+		allowed    []string
+		rejected   []string
+	}{
+		{
+			name:    "no filters",
+			allowed: []string{"https://example.com/anything"},
+		},
+		{
+			name:     "regex include matches anywhere",
+			include:  []string{`/docs/`, `\.html$`},
+			allowed:  []string{"https://example.com/docs/a", "https://example.com/page.html"},
+			rejected: []string{"https://example.com/blog/a"},
+		},
+		{
+			name:     "glob matches the whole URL across slashes",
+			include:  []string{"glob:https://example.com/guide/*"},
+			allowed:  []string{"https://example.com/guide/a/b/c"},
+			rejected: []string{"https://example.com/guides", "https://other.example/https://example.com/guide/x"},
+		},
+		{
+			name:     "exclude wins over include",
+			include:  []string{"/docs/"},
+			exclude:  []string{"glob:*/fr/*", `\?print=1`},
+			allowed:  []string{"https://example.com/docs/en/a"},
+			rejected: []string{"https://example.com/docs/fr/a", "https://example.com/docs/a?print=1"},
+		},
+		{
+			name:       "path prefix ignores host and query",
+			pathPrefix: "/api/",
+			allowed:    []string{"https://example.com/api/v1?x=/blog/"},
+			rejected:   []string{"https://example.com/blog/?x=/api/", "https://example.com/api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newURLFilter(tt.include, tt.exclude, tt.pathPrefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, u := range tt.allowed {
+				if !f.allow(u) {
+					t.Errorf("allow(%s) = false, want true", u)
+				}
+			}
+			for _, u := range tt.rejected {
+				if f.allow(u) {
+					t.Errorf("allow(%s) = true, want false", u)
+				}
+			}
+		})
+	}
+}
+
+func TestNewURLFilterErrors(t *testing.T) {
+	tests := []struct {
+		include, exclude []string
+		want             string
+	}{
+		{include: []string{"("}, want: "--include: invalid pattern"},
+		{exclude: []string{"glob:[a"}, want: "--exclude: invalid glob"},
+	}
+	for _, tt := range tests {
+		if _, err := newURLFilter(tt.include, tt.exclude, ""); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("newURLFilter(%q, %q) error = %v, want %q", tt.include, tt.exclude, err, tt.want)
+		}
+	}
+}
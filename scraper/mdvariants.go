@@ -0,0 +1,230 @@
+package scraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSiblingMisses is how many pages of a host may lack a .md sibling
+// before the host stops being probed. One hit keeps probing on for good.
+const maxSiblingMisses = 3
+
+// maxMarkdownBytes caps probed llms.txt and sibling bodies.
+const maxMarkdownBytes = 10 << 20
+
+// mdProber looks for markdown a site publishes itself: /llms.txt indexes
+// and page.md siblings of HTML pages (https://llmstxt.org).
+type mdProber struct {
+	client  *http.Client
+	allowed func(*url.URL) bool // robots.txt check, nil = allow all
+
+	mu    sync.Mutex
+	hosts map[string]*siblingHost
+}
+
+type siblingHost struct {
+	found   bool // a sibling was served; keep probing every page
+	misses  int
+	probing bool // first probe in flight; others fetch HTML meanwhile
+}
+
+// mdVariant is a markdown document fetched by a probe.
+type mdVariant struct {
+	url     string
+	body    string
+	status  int
+	ctype   string
+	headers http.Header
+}
+
+func newMDProber(transport http.RoundTripper, timeout time.Duration, allowed func(*url.URL) bool) *mdProber {
+	return &mdProber{
+		client:  &http.Client{Transport: transport, Timeout: timeout},
+		allowed: allowed,
+		hosts:   make(map[string]*siblingHost),
+	}
+}
+
+// get fetches rawURL and returns it if it is markdown rather than an HTML
+// page or error document served in its place.
+func (p *mdProber) get(ctx context.Context, rawURL string) (*mdVariant, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (p.allowed != nil && !p.allowed(u)) {
+		return nil, false
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, false
+	}
+	req.Header.Set("Accept", "text/markdown, text/plain;q=0.9")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+	ct := resp.Header.Get("Content-Type")
+	if !isMarkdownType(ct, resp.Request.URL) {
+		return nil, false
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMarkdownBytes))
+	if err != nil {
+		return nil, false
+	}
+	body := string(data)
+	// Catch-all routes answer any path with the HTML app shell.
+	if strings.HasPrefix(strings.TrimSpace(body), "<") {
+		return nil, false
+	}
+	return &mdVariant{
+		url:     resp.Request.URL.String(),
+		body:    body,
+		status:  resp.StatusCode,
+		ctype:   ct,
+		headers: resp.Header,
+	}, true
+}
+
+// isMarkdownType reports whether a response is markdown, either by content
+// type or as a plain-text file with a markdown extension.
+func isMarkdownType(contentType string, u *url.URL) bool {
+	ct := strings.ToLower(contentType)
+	if strings.Contains(ct, "text/markdown") || strings.Contains(ct, "text/x-markdown") {
+		return true
+	}
+	if strings.Contains(ct, "text/plain") || ct == "" {
+		p := strings.ToLower(u.Path)
+		return strings.HasSuffix(p, ".md") || strings.HasSuffix(p, ".txt")
+	}
+	return false
+}
+
+// siblingURLs lists where a page's markdown twin may live: page.md or
+// page.html.md next to it, and index.html.md / index.md for directories.
+func siblingURLs(u *url.URL) []string {
+	if strings.HasSuffix(strings.ToLower(u.Path), ".md") {
+		return nil
+	}
+	base := *u
+	base.RawQuery = ""
+	base.Fragment = ""
+	p := base.Path
+	if p == "" || strings.HasSuffix(p, "/") {
+		var out []string
+		for _, name := range []string{"index.html.md", "index.md"} {
+			v := base
+			v.Path = path.Join(p, name)
+			out = append(out, v.String())
+		}
+		return out
+	}
+	out := []string{base.String() + ".md"}
+	if ext := path.Ext(p); ext == ".html" || ext == ".htm" {
+		v := base
+		v.Path = strings.TrimSuffix(p, ext) + ".md"
+		out = append(out, v.String())
+	}
+	return out
+}
+
+// sibling returns the published markdown twin of page u, if its host
+// serves them. Hosts that miss maxSiblingMisses times in a row before
+// any hit are no longer probed.
+func (p *mdProber) sibling(ctx context.Context, u *url.URL) (*mdVariant, bool) {
+	p.mu.Lock()
+	h, ok := p.hosts[u.Host]
+	if !ok {
+		h = &siblingHost{}
+		p.hosts[u.Host] = h
+	}
+	if !h.found && (h.misses >= maxSiblingMisses || h.probing) {
+		p.mu.Unlock()
+		return nil, false
+	}
+	h.probing = !h.found
+	p.mu.Unlock()
+
+	for _, candidate := range siblingURLs(u) {
+		if v, ok := p.get(ctx, candidate); ok {
+			p.mu.Lock()
+			h.found, h.probing = true, false
+			p.mu.Unlock()
+			return v, true
+		}
+	}
+
+	p.mu.Lock()
+	h.probing = false
+	if !h.found {
+		h.misses++
+	}
+	p.mu.Unlock()
+	return nil, false
+}
+
+// markFound records that host publishes markdown twins, e.g. because its
+// llms.txt links to .md files.
+func (p *mdProber) markFound(host string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hosts[host] = &siblingHost{found: true}
+}
+
+// expandLLMsTxt returns the seeds followed by the pages linked from each
+// seed host's /llms.txt. Links outside allowed or rejected by keep are
+// dropped and at most limit URLs are returned when limit > 0.
+func expandLLMsTxt(ctx context.Context, p *mdProber, seeds, allowed []string, keep func(string) bool, limit int) []string {
+	out := slices.Clone(seeds)
+	seen := make(map[string]bool, len(seeds))
+	for _, s := range seeds {
+		seen[s] = true
+	}
+	origins := make(map[string]bool)
+	for _, raw := range seeds {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		origin := u.Scheme + "://" + u.Host
+		if origins[origin] {
+			continue
+		}
+		origins[origin] = true
+
+		v, ok := p.get(ctx, origin+"/llms.txt")
+		if !ok {
+			continue
+		}
+		for _, link := range extractMarkdownLinks(v.body, v.url) {
+			if limit > 0 && len(out) >= limit {
+				return out
+			}
+			lu, err := url.Parse(link)
+			if err != nil || seen[link] {
+				continue
+			}
+			if allowed != nil && !slices.Contains(allowed, lu.Hostname()) {
+				continue
+			}
+			if keep != nil && !keep(link) {
+				continue
+			}
+			if strings.HasSuffix(strings.ToLower(lu.Path), ".md") {
+				p.markFound(lu.Host)
+			}
+			seen[link] = true
+			out = append(out, link)
+		}
+	}
+	return out
+}
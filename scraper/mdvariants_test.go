@@ -0,0 +1,178 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSiblingURLs(t *testing.T) {
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{"https://example.com/docs/intro", []string{"https://example.com/docs/intro.md"}},
+		{"https://example.com/docs/intro?lang=en#top", []string{"https://example.com/docs/intro.md"}},
+		{"https://example.com/guide.html", []string{"https://example.com/guide.html.md", "https://example.com/guide.md"}},
+		{"https://example.com/docs/", []string{"https://example.com/docs/index.html.md", "https://example.com/docs/index.md"}},
+		{"https://example.com", []string{"https://example.com/index.html.md", "https://example.com/index.md"}},
+		{"https://example.com/README.md", nil},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := siblingURLs(u); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("siblingURLs(%s) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsMarkdownType(t *testing.T) {
+	tests := []struct {
+		ctype, path string
+		want        bool
+	}{
+		{"text/markdown; charset=utf-8", "/a", true},
+		{"text/x-markdown", "/a", true},
+		{"text/plain", "/a.md", true},
+		{"text/plain", "/llms.txt", true},
+		{"", "/a.md", true},
+		{"text/plain", "/a", false},
+		{"text/html", "/a.md", false},
+	}
+	for _, tt := range tests {
+		if got := isMarkdownType(tt.ctype, &url.URL{Path: tt.path}); got != tt.want {
+			t.Errorf("isMarkdownType(%q, %q) = %v, want %v", tt.ctype, tt.path, got, tt.want)
+		}
+	}
+}
+
+// newMarkdownSite serves /docs/intro.md as markdown, /spa.md as the HTML app
+// shell a catch-all route returns, and an llms.txt linking to both.
+func newMarkdownSite(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var probes atomic.Int64
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".md") {
+			probes.Add(1)
+		}
+		switch r.URL.Path {
+		case "/llms.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprintf(w, "# Example\n\n- [Intro](%[1]s/docs/intro.md)\n- [API](/api)\n- [Other](https://other.example/x.md)\n- [Intro again](%[1]s/docs/intro.md)\n", srv.URL)
+		case "/docs/intro.md":
+			w.Header().Set("Content-Type", "text/markdown")
+			fmt.Fprint(w, "# Intro\n\nHello.")
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<!doctype html><div id=app></div>")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &probes
+}
+
+func TestMDProberSibling(t *testing.T) {
+	srv, probes := newMarkdownSite(t)
+	p := newMDProber(http.DefaultTransport, 5*time.Second, nil)
+	ctx := context.Background()
+
+	u, _ := url.Parse(srv.URL + "/docs/intro")
+	v, ok := p.sibling(ctx, u)
+	if !ok || v.body != "# Intro\n\nHello." || v.url != srv.URL+"/docs/intro.md" {
+		t.Fatalf("sibling(/docs/intro) = %+v, %v", v, ok)
+	}
+
+	// An HTML shell served for a .md path is not markdown.
+	u, _ = url.Parse(srv.URL + "/spa")
+	if v, ok := p.sibling(ctx, u); ok {
+		t.Errorf("sibling(/spa) = %+v, want none", v)
+	}
+	// A host that served a sibling keeps being probed.
+	probes.Store(0)
+	for range maxSiblingMisses + 2 {
+		p.sibling(ctx, u)
+	}
+	if got := probes.Load(); got != maxSiblingMisses+2 {
+		t.Errorf("probed %d times after a hit, want %d", got, maxSiblingMisses+2)
+	}
+}
+
+func TestMDProberGivesUp(t *testing.T) {
+	srv, probes := newMarkdownSite(t)
+	p := newMDProber(http.DefaultTransport, 5*time.Second, nil)
+	for i := range maxSiblingMisses + 3 {
+		u, _ := url.Parse(fmt.Sprintf("%s/page%d", srv.URL, i))
+		if _, ok := p.sibling(context.Background(), u); ok {
+			t.Fatalf("sibling(%s) found one", u)
+		}
+	}
+	if got := probes.Load(); got != maxSiblingMisses {
+		t.Errorf("probed %d times, want %d before giving up", got, maxSiblingMisses)
+	}
+}
+
+func TestMDProberRespectsRobots(t *testing.T) {
+	srv, probes := newMarkdownSite(t)
+	p := newMDProber(http.DefaultTransport, 5*time.Second, func(u *url.URL) bool {
+		return !strings.HasPrefix(u.Path, "/docs/")
+	})
+	u, _ := url.Parse(srv.URL + "/docs/intro")
+	if _, ok := p.sibling(context.Background(), u); ok || probes.Load() != 0 {
+		t.Errorf("fetched a sibling robots.txt disallows")
+	}
+}
+
+func TestExpandLLMsTxt(t *testing.T) {
+	srv, _ := newMarkdownSite(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	hostname, _, _ := strings.Cut(host, ":")
+	seed := srv.URL + "/"
+
+	tests := []struct {
+		name    string
+		allowed []string
+		keep    func(string) bool
+		limit   int
+		want    []string
+	}{
+		{
+			name: "all links",
+			want: []string{seed, srv.URL + "/docs/intro.md", srv.URL + "/api", "https://other.example/x.md"},
+		},
+		{
+			name:    "allowed hosts",
+			allowed: []string{hostname},
+			want:    []string{seed, srv.URL + "/docs/intro.md", srv.URL + "/api"},
+		},
+		{
+			name: "filtered",
+			keep: func(u string) bool { return !strings.HasSuffix(u, "/api") },
+			want: []string{seed, srv.URL + "/docs/intro.md", "https://other.example/x.md"},
+		},
+		{
+			name:  "limited",
+			limit: 2,
+			want:  []string{seed, srv.URL + "/docs/intro.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newMDProber(http.DefaultTransport, 5*time.Second, nil)
+			got := expandLLMsTxt(context.Background(), p, []string{seed}, tt.allowed, tt.keep, tt.limit)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("expandLLMsTxt() =\n%q\nwant\n%q", got, tt.want)
+			}
+			// Linking to .md files marks the host as publishing siblings.
+			if h := p.hosts[host]; h == nil || !h.found {
+				t.Errorf("host not marked as publishing markdown")
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package scraper
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Metadata describes a fetched page. Fields the page does not provide are
+// left empty.
+type Metadata struct {
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Canonical   string            `json:"canonical,omitempty"`
+	Language    string            `json:"language,omitempty"`
+	OpenGraph   map[string]string `json:"open_graph,omitempty"` // og:* properties, keyed without the prefix
+	Twitter     map[string]string `json:"twitter,omitempty"`    // twitter:* cards, keyed without the prefix
+	Published   time.Time         `json:"published,omitzero"`
+	Modified    time.Time         `json:"modified,omitzero"`
+
+	FinalURL    string        `json:"final_url,omitempty"` // after redirects
+	StatusCode  int           `json:"status,omitempty"`
+	ContentType string        `json:"content_type,omitempty"`
+	Size        int           `json:"size,omitempty"`     // response body bytes
+	Duration    time.Duration `json:"duration,omitempty"` // from request start, including any crawl delay
+}
+
+// dateLayouts are the timestamp formats seen in article meta tags.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// htmlMetadata extracts descriptive metadata from an HTML document. It must
+// run before any content scoping removes parts of doc. Relative canonical
+// URLs are resolved against base.
+func htmlMetadata(doc *goquery.Document, base func(string) string, headers *http.Header) Metadata {
+	var m Metadata
+
+	doc.Find("meta").Each(func(_ int, s *goquery.Selection) {
+		content, ok := s.Attr("content")
+		if !ok {
+			return
+		}
+		content = strings.TrimSpace(content)
+		key, _ := s.Attr("property")
+		if key == "" {
+			key, _ = s.Attr("name")
+		}
+		if key == "" {
+			key, _ = s.Attr("itemprop")
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		switch {
+		case strings.HasPrefix(key, "og:"):
+			if m.OpenGraph == nil {
+				m.OpenGraph = make(map[string]string)
+			}
+			m.OpenGraph[strings.TrimPrefix(key, "og:")] = content
+		case strings.HasPrefix(key, "twitter:"):
+			if m.Twitter == nil {
+				m.Twitter = make(map[string]string)
+			}
+			m.Twitter[strings.TrimPrefix(key, "twitter:")] = content
+		case key == "description":
+			m.Description = content
+		case key == "article:published_time", key == "datepublished", key == "date":
+			if m.Published.IsZero() {
+				m.Published = parseDate(content)
+			}
+		case key == "article:modified_time", key == "datemodified", key == "last-modified":
+			if m.Modified.IsZero() {
+				m.Modified = parseDate(content)
+			}
+		}
+	})
+
+	m.Title = pageTitle(doc)
+	if m.Description == "" {
+		m.Description = m.OpenGraph["description"]
+	}
+	if m.Modified.IsZero() {
+		m.Modified = parseDate(m.OpenGraph["updated_time"])
+	}
+	if href, ok := doc.Find(`link[rel="canonical"]`).Attr("href"); ok && strings.TrimSpace(href) != "" {
+		m.Canonical = base(strings.TrimSpace(href))
+	}
+	if lang, ok := doc.Find("html").Attr("lang"); ok {
+		m.Language = strings.TrimSpace(lang)
+	}
+
+	if headers != nil {
+		if m.Language == "" {
+			m.Language = headers.Get("Content-Language")
+		}
+		if m.Modified.IsZero() {
+			if t, err := http.ParseTime(headers.Get("Last-Modified")); err == nil {
+				m.Modified = t
+			}
+		}
+	}
+	return m
+}
+
+// markdownMetadata extracts what a native markdown response offers: the
+// first top-level heading as title, plus response headers.
+func markdownMetadata(md string, headers *http.Header) Metadata {
+	var m Metadata
+	sc := bufio.NewScanner(strings.NewReader(md))
+	for sc.Scan() {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "# "); ok {
+			m.Title = strings.TrimSpace(title)
+			break
+		}
+	}
+	if headers != nil {
+		m.Language = headers.Get("Content-Language")
+		if t, err := http.ParseTime(headers.Get("Last-Modified")); err == nil {
+			m.Modified = t
+		}
+	}
+	return m
+}
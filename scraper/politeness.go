@@ -0,0 +1,161 @@
+package scraper
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultHostParallelism caps concurrent requests to one host when
+	// Options.HostParallelism is unset.
+	defaultHostParallelism = 8
+	// throttleDelay is the first adaptive delay after a 429 or 503; each
+	// further one doubles it.
+	throttleDelay = time.Second
+	// spikeDelay is the first adaptive delay after a latency spike.
+	spikeDelay = 250 * time.Millisecond
+	// maxAdaptiveDelay caps the adaptive delay between requests to a host.
+	maxAdaptiveDelay = time.Minute
+	// spikeFactor is how many times slower than usual a response must be
+	// to count as a latency spike.
+	spikeFactor = 3
+	// minSpikeLatency keeps fast hosts from tripping the spike check on
+	// ordinary jitter.
+	minSpikeLatency = 500 * time.Millisecond
+)
+
+// hostLimiter bounds concurrent requests per host and spaces out their
+// starts by the configured delay plus random jitter, robots.txt
+// Crawl-delay, and an adaptive delay. The adaptive delay grows while a host
+// answers 429/503 or responds much slower than usual, and decays again as
+// it recovers. It is safe for concurrent use.
+type hostLimiter struct {
+	parallelism int
+	delay       time.Duration
+	jitter      time.Duration
+	onSlow      func(u *url.URL, delay time.Duration) // adaptive delay went up
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	slots    chan struct{}
+	next     time.Time     // earliest start of the next request
+	adaptive time.Duration // extra spacing while the host is struggling
+	latency  time.Duration // moving average of healthy response times
+}
+
+func newHostLimiter(parallelism int, delay, jitter time.Duration) *hostLimiter {
+	if parallelism <= 0 {
+		parallelism = defaultHostParallelism
+	}
+	return &hostLimiter{
+		parallelism: parallelism,
+		delay:       delay,
+		jitter:      jitter,
+		hosts:       make(map[string]*hostState),
+	}
+}
+
+// state returns host's entry, creating it on first use. The caller holds
+// l.mu.
+func (l *hostLimiter) state(host string) *hostState {
+	s, ok := l.hosts[host]
+	if !ok {
+		s = &hostState{slots: make(chan struct{}, l.parallelism)}
+		l.hosts[host] = s
+	}
+	return s
+}
+
+// acquire waits for a free slot on host and then for its next start time,
+// spacing starts by at least minDelay (robots.txt Crawl-delay). The
+// returned func frees the slot. It reports false if ctx ends first.
+func (l *hostLimiter) acquire(ctx context.Context, host string, minDelay time.Duration) (func(), bool) {
+	l.mu.Lock()
+	s := l.state(host)
+	l.mu.Unlock()
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, false
+	}
+	release := sync.OnceFunc(func() { <-s.slots })
+
+	l.mu.Lock()
+	now := time.Now()
+	start := s.next
+	if start.Before(now) {
+		start = now
+	}
+	gap := max(l.delay, minDelay) + s.adaptive
+	if l.jitter > 0 {
+		gap += rand.N(l.jitter)
+	}
+	s.next = start.Add(gap)
+	l.mu.Unlock()
+
+	if !sleepCtx(ctx, time.Until(start)) {
+		release()
+		return nil, false
+	}
+	return release, true
+}
+
+// observe adjusts u's host's adaptive delay after a response (status) or
+// failure (err) that took elapsed.
+func (l *hostLimiter) observe(u *url.URL, status int, elapsed time.Duration, err error) {
+	l.mu.Lock()
+	s := l.state(u.Host)
+	prev := s.adaptive
+
+	switch {
+	case status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable:
+		s.adaptive = min(max(2*s.adaptive, throttleDelay), maxAdaptiveDelay)
+	case err != nil || status >= 500:
+		// Failures say nothing about the host's pace.
+	case s.latency > 0 && elapsed > spikeFactor*s.latency && elapsed > minSpikeLatency:
+		s.adaptive = min(max(2*s.adaptive, spikeDelay), maxAdaptiveDelay)
+	default:
+		if s.latency == 0 {
+			s.latency = elapsed
+		} else {
+			s.latency = (4*s.latency + elapsed) / 5
+		}
+		s.adaptive = s.adaptive * 3 / 4
+		if s.adaptive < 50*time.Millisecond {
+			s.adaptive = 0
+		}
+	}
+	slowed := s.adaptive > prev
+	delay := s.adaptive
+	l.mu.Unlock()
+
+	if slowed && l.onSlow != nil {
+		l.onSlow(u, delay)
+	}
+}
+
+// observedTransport reports every network round trip to a hostLimiter.
+// It sits below the cache so replayed responses do not skew timings.
+type observedTransport struct {
+	next    http.RoundTripper
+	limiter *hostLimiter
+}
+
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	t.limiter.observe(req.URL, status, time.Since(start), err)
+	return resp, err
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// proxyRouter picks the proxy for each request. Proxies given for a domain
+// serve that domain and its subdomains; the rest form a pool from which
+// each host is assigned one, round robin, and keeps it for the whole run
+// so its session and rate limits stay on one exit. Hosts matching
+// NO_PROXY always connect directly. Without any --proxy, HTTP_PROXY and
+// HTTPS_PROXY apply as usual.
+type proxyRouter struct {
+	routes  []proxyRoute
+	pool    []*url.URL
+	env     func(*url.URL) (*url.URL, error) // HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	noProxy func(*url.URL) (*url.URL, error) // NO_PROXY alone: nil means bypass
+
+	mu       sync.Mutex
+	assigned map[string]*url.URL // host → pool proxy
+	next     int
+}
+
+type proxyRoute struct {
+	domain string
+	proxy  *url.URL
+}
+
+// newProxyRouter parses --proxy values: "scheme://[user:pass@]host:port",
+// or "domain=scheme://host:port" to route one domain.
+func newProxyRouter(specs []string) (*proxyRouter, error) {
+	env := httpproxy.FromEnvironment()
+	// A stand-in proxy URL turns the NO_PROXY matcher into a bypass test.
+	bypass := httpproxy.Config{HTTPProxy: "http://proxy", HTTPSProxy: "http://proxy", NoProxy: env.NoProxy}
+	r := &proxyRouter{
+		env:      env.ProxyFunc(),
+		noProxy:  bypass.ProxyFunc(),
+		assigned: make(map[string]*url.URL),
+	}
+	for _, spec := range specs {
+		domain, raw := "", spec
+		// A domain comes before "=", ahead of the scheme's "://".
+		if i := strings.Index(spec, "="); i >= 0 && i < strings.Index(spec, "://") {
+			domain, raw = normalizeDomain(spec[:i]), spec[i+1:]
+			if domain == "" {
+				return nil, fmt.Errorf("invalid proxy %q: empty domain", spec)
+			}
+		}
+		u, err := parseProxyURL(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %q: %w", spec, err)
+		}
+		if domain != "" {
+			r.routes = append(r.routes, proxyRoute{domain: domain, proxy: u})
+		} else {
+			r.pool = append(r.pool, u)
+		}
+	}
+	return r, nil
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("scheme must be http, https, socks5 or socks5h")
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+	return u, nil
+}
+
+// proxy is an http.Transport Proxy func. A nil URL means a direct
+// connection.
+func (r *proxyRouter) proxy(req *http.Request) (*url.URL, error) {
+	if p, err := r.noProxy(req.URL); err != nil || p == nil {
+		return nil, err
+	}
+	host := strings.ToLower(req.URL.Hostname())
+
+	// The most specific domain route wins.
+	var best *proxyRoute
+	for i, route := range r.routes {
+		if domainMatch(host, route.domain) && (best == nil || len(route.domain) > len(best.domain)) {
+			best = &r.routes[i]
+		}
+	}
+	if best != nil {
+		return best.proxy, nil
+	}
+	if len(r.pool) == 0 {
+		return r.env(req.URL)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.assigned[host]
+	if !ok {
+		p = r.pool[r.next%len(r.pool)]
+		r.next++
+		r.assigned[host] = p
+	}
+	return p, nil
+}
@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func proxyFor(t *testing.T, r *proxyRouter, rawURL string) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := r.proxy(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil {
+		return "direct"
+	}
+	return p.String()
+}
+
+func TestProxyRouter(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://env-proxy:3128")
+	t.Setenv("HTTPS_PROXY", "http://env-proxy:3128")
+	t.Setenv("NO_PROXY", "internal.example,.corp.example")
+
+	tests := []struct {
+		name  string
+		specs []string
+		urls  []string
+		want  []string
+	}{
+		{
+			name: "environment without --proxy",
+			urls: []string{"http://example.com/", "https://internal.example/"},
+			want: []string{"http://env-proxy:3128", "direct"},
+		},
+		{
+			name:  "pool assigns hosts round robin and keeps them",
+			specs: []string{"http://p1:8080", "socks5://user:pw@p2:1080"},
+			urls: []string{
+				"https://a.example/", "https://b.example/", "https://c.example/",
+				"https://a.example/other", "https://b.example/again",
+			},
+			want: []string{
+				"http://p1:8080", "socks5://user:pw@p2:1080", "http://p1:8080",
+				"http://p1:8080", "socks5://user:pw@p2:1080",
+			},
+		},
+		{
+			name:  "domain routes beat the pool, most specific first",
+			specs: []string{"http://pool:8080", "example.com=http://site:8080", "api.example.com=socks5h://api:1080"},
+			urls:  []string{"https://example.com/", "https://www.example.com/", "https://v1.api.example.com/", "https://other.example/"},
+			want:  []string{"http://site:8080", "http://site:8080", "socks5h://api:1080", "http://pool:8080"},
+		},
+		{
+			name:  "NO_PROXY bypasses routes and the pool",
+			specs: []string{"http://pool:8080", "corp.example=http://site:8080"},
+			urls:  []string{"https://internal.example/", "https://git.corp.example/"},
+			want:  []string{"direct", "direct"},
+		},
+		{
+			name:  "routes alone leave other hosts on the environment",
+			specs: []string{"example.com=http://site:8080"},
+			urls:  []string{"https://example.com/", "http://other.example/"},
+			want:  []string{"http://site:8080", "http://env-proxy:3128"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newProxyRouter(tt.specs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, u := range tt.urls {
+				got = append(got, proxyFor(t, r, u))
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("proxies = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewProxyRouterErrors(t *testing.T) {
+	tests := []struct {
+		spec, want string
+	}{
+		{"ftp://proxy:21", "scheme must be"},
+		{"proxy:8080", "scheme must be"},
+		{"http://", "missing host"},
+		{"=http://proxy:8080", "empty domain"},
+	}
+	for _, tt := range tests {
+		_, err := newProxyRouter([]string{tt.spec})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("newProxyRouter(%q) error = %v, want %q", tt.spec, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package scraper
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// contentScope narrows an HTML page to the part worth converting.
+type contentScope struct {
+	selector    cascadia.Selector // keep only matching elements, nil = whole page
+	exclude     cascadia.Selector // drop matching elements, nil = none
+	readability bool              // keep only the highest scoring article block
+}
+
+func newContentScope(selector, exclude string, readability bool) (*contentScope, error) {
+	cs := &contentScope{readability: readability}
+	if selector != "" {
+		sel, err := cascadia.Compile(selector)
+		if err != nil {
+			return nil, fmt.Errorf("--selector: invalid CSS selector %q: %w", selector, err)
+		}
+		cs.selector = sel
+	}
+	if exclude != "" {
+		sel, err := cascadia.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("--exclude-selector: invalid CSS selector %q: %w", exclude, err)
+		}
+		cs.exclude = sel
+	}
+	return cs, nil
+}
+
+// active reports whether the scope changes anything, so callers can skip
+// the extra parse for plain conversions.
+func (cs *contentScope) active() bool {
+	return cs.selector != nil || cs.exclude != nil || cs.readability
+}
+
+// apply returns the scoped HTML of doc. The page title is kept as a
+// heading when the extracted content does not already start with it.
+func (cs *contentScope) apply(doc *goquery.Document) string {
+	title := pageTitle(doc)
+
+	if cs.exclude != nil {
+		doc.FindMatcher(cs.exclude).Remove()
+	}
+
+	content := doc.Find("body")
+	if content.Length() == 0 {
+		content = doc.Selection
+	}
+	if cs.selector != nil {
+		content = doc.FindMatcher(cs.selector)
+	}
+	if cs.readability {
+		content = extractArticle(content)
+	}
+
+	var b strings.Builder
+	if title != "" && !strings.EqualFold(strings.TrimSpace(content.Find("h1").First().Text()), title) {
+		b.WriteString("<h1>")
+		b.WriteString(html.EscapeString(title))
+		b.WriteString("</h1>\n")
+	}
+	content.Each(func(_ int, s *goquery.Selection) {
+		h, err := goquery.OuterHtml(s)
+		if err == nil {
+			b.WriteString(h)
+			b.WriteString("\n")
+		}
+	})
+	return b.String()
+}
+
+// pageTitle returns the document's title, preferring Open Graph metadata
+// which usually omits the " | Site Name" suffix.
+func pageTitle(doc *goquery.Document) string {
+	if t, ok := doc.Find(`meta[property="og:title"]`).Attr("content"); ok && strings.TrimSpace(t) != "" {
+		return strings.TrimSpace(t)
+	}
+	return strings.TrimSpace(doc.Find("title").First().Text())
+}
+
+var (
+	// unlikelyRe matches class/id values of page chrome rather than content.
+	unlikelyRe = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|consent|disqus|extra|foot|header|legends|menu|modal|nav|popup|promo|related|remark|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|toc|tweet|ad-break|agegate|pagination|pager`)
+	// likelyRe rescues elements that match unlikelyRe but look like content.
+	likelyRe   = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story|prose|markdown|docs?`)
+	negativeRe = regexp.MustCompile(`(?i)hidden|banner|combx|comment|com-|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget|cookie|nav|menu`)
+)
+
+// chromeTags never hold article text.
+const chromeTags = "script, style, noscript, template, nav, header, footer, aside, form, iframe, svg, button, dialog"
+
+// extractArticle scores block elements the way Arc90's Readability does:
+// each paragraph awards points for its length and commas to its parent and
+// half as many to its grandparent; candidates are weighted by tag and
+// class/id hints and penalized by link density. The best candidate is
+// returned together with siblings that score close to it. If nothing
+// scores, root is returned unchanged.
+func extractArticle(root *goquery.Selection) *goquery.Selection {
+	root.Find(chromeTags).Remove()
+	root.Find("*").Each(func(_ int, s *goquery.Selection) {
+		if s.Is("body, html, article, main") {
+			return
+		}
+		hint := attrHint(s)
+		if hint != "" && unlikelyRe.MatchString(hint) && !likelyRe.MatchString(hint) {
+			s.Remove()
+		}
+	})
+
+	scores := make(map[*html.Node]float64)
+	var order []*goquery.Selection
+	score := func(s *goquery.Selection, points float64) {
+		n := s.Get(0)
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(s)
+			order = append(order, s)
+		}
+		scores[n] += points
+	}
+
+	root.Find("p, pre, td, blockquote, li").Each(func(_ int, p *goquery.Selection) {
+		text := strings.TrimSpace(p.Text())
+		if len(text) < 25 {
+			return
+		}
+		points := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		parent := p.Parent()
+		if parent.Length() == 0 {
+			return
+		}
+		score(parent, points)
+		if gp := parent.Parent(); gp.Length() > 0 {
+			score(gp, points/2)
+		}
+	})
+
+	var (
+		top      *goquery.Selection
+		topScore float64
+	)
+	for _, s := range order {
+		n := s.Get(0)
+		scores[n] *= 1 - linkDensity(s)
+		if top == nil || scores[n] > topScore {
+			top, topScore = s, scores[n]
+		}
+	}
+	if top == nil {
+		return root
+	}
+
+	// Articles are often split across sibling blocks under one parent.
+	threshold := math.Max(10, topScore*0.2)
+	parent := top.Parent()
+	if parent.Length() == 0 {
+		return top
+	}
+	keep := top
+	parent.Children().Each(func(_ int, sib *goquery.Selection) {
+		n := sib.Get(0)
+		if n == top.Get(0) {
+			return
+		}
+		if s, ok := scores[n]; ok && s >= threshold {
+			keep = keep.AddSelection(sib)
+			return
+		}
+		if sib.Is("p") {
+			text := strings.TrimSpace(sib.Text())
+			if len(text) > 80 && linkDensity(sib) < 0.25 {
+				keep = keep.AddSelection(sib)
+			}
+		}
+	})
+	// AddSelection appends, so restore document order for output.
+	return parent.Children().FilterSelection(keep)
+}
+
+func initialScore(s *goquery.Selection) float64 {
+	var base float64
+	switch goquery.NodeName(s) {
+	case "article", "main":
+		base = 10
+	case "div", "section":
+		base = 5
+	case "pre", "td", "blockquote":
+		base = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		base = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		base = -5
+	}
+	hint := attrHint(s)
+	if hint != "" {
+		if positiveRe.MatchString(hint) {
+			base += 25
+		}
+		if negativeRe.MatchString(hint) {
+			base -= 25
+		}
+	}
+	return base
+}
+
+// attrHint joins the class and id of s for pattern matching.
+func attrHint(s *goquery.Selection) string {
+	class, _ := s.Attr("class")
+	id, _ := s.Attr("id")
+	return strings.TrimSpace(class + " " + id)
+}
+
+// linkDensity is the fraction of s's text that sits inside links.
+func linkDensity(s *goquery.Selection) float64 {
+	total := len(strings.TrimSpace(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	var linked int
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		linked += len(strings.TrimSpace(a.Text()))
+	})
+	return float64(linked) / float64(total)
+}
@@ -0,0 +1,140 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const articlePage = `<html><head><title>Fallback title</title>
+<meta property="og:title" content="Growing Tomatoes"></head>
+<body>
+<header><a href="/">Home</a> <a href="/blog">Blog</a></header>
+<nav class="menu"><ul><li><a href="/a">Gardening tips and tricks for everyone</a></li></ul></nav>
+<div class="sidebar"><p>Subscribe to our newsletter, for weekly updates, offers and more.</p></div>
+<div class="post-content">
+<p>Tomatoes need at least six hours of sun a day, warm soil, and steady watering to do well.</p>
+<p>Start seeds indoors, six to eight weeks before the last frost, then harden them off outside.</p>
+<p class="note">Stake or cage each plant early, before the stems get heavy with fruit.</p>
+</div>
+<div class="comments"><p>Great post, thanks for sharing, I will try this in my own garden.</p></div>
+<footer><p>Copyright Example Gardens, all rights reserved, since 2001.</p></footer>
+</body></html>`
+
+func TestContentScope(t *testing.T) {
+	tests := []struct {
+		name        string
+		selector    string
+		exclude     string
+		readability bool
+		want        []string
+		notWant     []string
+	}{
+		{
+			name: "whole page",
+			want: []string{"<h1>Growing Tomatoes</h1>", "six hours of sun", "Subscribe", "Great post"},
+		},
+		{
+			name:        "readability keeps the article",
+			readability: true,
+			want:        []string{"<h1>Growing Tomatoes</h1>", "six hours of sun", "Start seeds", "Stake or cage"},
+			notWant:     []string{"Subscribe", "Great post", "Copyright", "Gardening tips"},
+		},
+		{
+			name:     "selector",
+			selector: ".post-content p:first-child",
+			want:     []string{"six hours of sun"},
+			notWant:  []string{"Start seeds", "Subscribe"},
+		},
+		{
+			name:    "exclude selector",
+			exclude: ".sidebar, .comments, .note",
+			want:    []string{"six hours of sun", "Copyright"},
+			notWant: []string{"Subscribe", "Great post", "Stake or cage"},
+		},
+		{
+			name:     "exclude applies before selector",
+			selector: ".post-content",
+			exclude:  ".note",
+			want:     []string{"Start seeds"},
+			notWant:  []string{"Stake or cage", "Copyright"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := newContentScope(tt.selector, tt.exclude, tt.readability)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(articlePage))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := cs.apply(doc)
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("missing %q in:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("unexpected %q in:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNewContentScopeInvalidSelector(t *testing.T) {
+	if _, err := newContentScope("div[", "", false); err == nil || !strings.Contains(err.Error(), "--selector") {
+		t.Errorf("invalid selector: got %v, want a --selector error", err)
+	}
+	if _, err := newContentScope("", "p:nope", false); err == nil || !strings.Contains(err.Error(), "--exclude-selector") {
+		t.Errorf("invalid exclude selector: got %v, want an --exclude-selector error", err)
+	}
+}
+
+func TestInitialScore(t *testing.T) {
+	tests := []struct {
+		html string
+		want float64
+	}{
+		{`<article>x</article>`, 10},
+		{`<div>x</div>`, 5},
+		{`<div class="post-body">x</div>`, 30},
+		{`<div class="sidebar">x</div>`, -20},
+		{`<ul><li>x</li></ul>`, -3},
+		{`<h2>x</h2>`, -5},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := doc.Find("body").Children().First()
+		if got := initialScore(s); got != tt.want {
+			t.Errorf("initialScore(%s) = %v, want %v", tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestLinkDensity(t *testing.T) {
+	tests := []struct {
+		html string
+		want float64
+	}{
+		{`<p>no links here</p>`, 0},
+		{`<p><a href="/">all link</a></p>`, 1},
+		{`<p>abc <a href="/">link</a></p>`, 0.5},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := linkDensity(doc.Find("p")); got != tt.want {
+			t.Errorf("linkDensity(%s) = %v, want %v", tt.html, got, tt.want)
+		}
+	}
+}
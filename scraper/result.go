@@ -1,42 +1,68 @@
 package scraper
 
-import "sync"
+import (
+	"slices"
+	"sync"
+)
 
 // Result represents a single scraped page.
 type Result struct {
-	URL      string
+	URL      string // as requested; Meta.FinalURL holds the post-redirect URL
 	Markdown string
-	Source   string // "native" or "converted"
+	Source   string // "native" (served as markdown), "sibling" (published page.md) or "converted"
 	Err      error
+	Meta     Metadata
+	Assets   map[string]string // downloaded image URL → file name in Options.AssetDir
+	Aliases  []string          // other URLs that served this page and were collapsed into it
 }
 
 // ResultStore is a thread-safe ordered collection of results.
 type ResultStore struct {
 	mu      sync.Mutex
 	results []Result
-	seen    map[string]bool
+	seen    map[string]int // URL → index in results, -1 once discarded
+	discard bool           // only deduplicate; results are streamed elsewhere
 }
 
 func NewResultStore() *ResultStore {
 	return &ResultStore{
-		seen: make(map[string]bool),
+		seen: make(map[string]int),
 	}
 }
 
-func (rs *ResultStore) Add(r Result) {
+// Add stores r unless a result for the same URL exists, reporting whether
+// it was added.
+func (rs *ResultStore) Add(r Result) bool {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
-	if rs.seen[r.URL] {
-		return
+	if _, ok := rs.seen[r.URL]; ok {
+		return false
+	}
+	if rs.discard {
+		rs.seen[r.URL] = -1
+		return true
 	}
-	rs.seen[r.URL] = true
+	rs.seen[r.URL] = len(rs.results)
 	rs.results = append(rs.results, r)
+	return true
+}
+
+// AddAlias records alias as another URL of the stored result for keptURL.
+func (rs *ResultStore) AddAlias(keptURL, alias string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	i, ok := rs.seen[keptURL]
+	if !ok || i < 0 || slices.Contains(rs.results[i].Aliases, alias) {
+		return
+	}
+	rs.results[i].Aliases = append(rs.results[i].Aliases, alias)
 }
 
+// Count returns how many results were added, including discarded ones.
 func (rs *ResultStore) Count() int {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
-	return len(rs.results)
+	return len(rs.seen)
 }
 
 func (rs *ResultStore) Results() []Result {
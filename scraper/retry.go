@@ -0,0 +1,128 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	// defaultRetryBackoff is the base delay when Options.RetryBackoff is unset.
+	defaultRetryBackoff = time.Second
+	// maxRetryDelay caps both computed backoff and server Retry-After values.
+	maxRetryDelay = 2 * time.Minute
+)
+
+// retryCounts tracks how often each URL has been retried, and which URL was
+// originally requested when the failure happened after a redirect. This
+// cannot live in the colly request context: Request.Visit hands the
+// parent's context to every link it queues, so children would inherit it.
+type retryCounts struct {
+	m sync.Map
+}
+
+type retryState struct {
+	attempt int
+	origin  string
+}
+
+// get returns the retry count for rawURL and the URL originally requested.
+func (rc *retryCounts) get(rawURL string) (int, string) {
+	v, _ := rc.m.Load(rawURL)
+	s, ok := v.(retryState)
+	if !ok {
+		return 0, rawURL
+	}
+	return s.attempt, s.origin
+}
+
+func (rc *retryCounts) set(rawURL string, attempt int, origin string) {
+	rc.m.Store(rawURL, retryState{attempt: attempt, origin: origin})
+}
+
+// isAborted reports whether err comes from colly aborting a request itself
+// rather than from the network or server.
+func isAborted(err error) bool {
+	return errors.Is(err, colly.ErrAbortedBeforeRequest) || errors.Is(err, colly.ErrAbortedAfterHeaders)
+}
+
+// isTransient reports whether a failed request is worth retrying: rate
+// limiting, server errors, timeouts and dropped connections.
+func isTransient(status int, err error) bool {
+	switch {
+	case status == http.StatusTooManyRequests, status == http.StatusRequestTimeout:
+		return true
+	case status >= 500:
+		return status != http.StatusNotImplemented && status != http.StatusHTTPVersionNotSupported
+	case status != 0:
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// retryDelay returns how long to wait before retry number attempt (1-based).
+// A Retry-After header wins; otherwise the delay doubles per attempt from
+// base with ±50% jitter so parallel workers do not retry in lockstep.
+func retryDelay(base time.Duration, attempt int, headers *http.Header) time.Duration {
+	if headers != nil {
+		if d, ok := parseRetryAfter(headers.Get("Retry-After"), time.Now()); ok {
+			return min(d, maxRetryDelay)
+		}
+	}
+	d := base << (attempt - 1)
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d/2 + rand.N(d)
+}
+
+// parseRetryAfter parses a Retry-After value given either as delay-seconds
+// or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(0, t.Sub(now)), true
+}
+
+// sleepCtx waits for d, returning false if ctx is cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
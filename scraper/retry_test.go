@@ -0,0 +1,80 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+		want   bool
+	}{
+		{http.StatusTooManyRequests, nil, true},
+		{http.StatusRequestTimeout, nil, true},
+		{http.StatusServiceUnavailable, nil, true},
+		{http.StatusBadGateway, nil, true},
+		{http.StatusNotImplemented, nil, false},
+		{http.StatusHTTPVersionNotSupported, nil, false},
+		{http.StatusNotFound, nil, false},
+		{http.StatusForbidden, nil, false},
+		{0, os.ErrDeadlineExceeded, true},
+		{0, fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{0, io.ErrUnexpectedEOF, true},
+		{0, errors.New("no such host"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.status, tt.err); got != tt.want {
+			t.Errorf("isTransient(%d, %v) = %v, want %v", tt.status, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"0", 0, true},
+		{"-5", 0, false},
+		{"Fri, 02 Jan 2026 03:04:35 GMT", 30 * time.Second, true},
+		{"Fri, 02 Jan 2026 03:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 1; attempt <= 4; attempt++ {
+		d := time.Second << (attempt - 1)
+		for range 20 {
+			if got := retryDelay(time.Second, attempt, nil); got < d/2 || got >= d/2+d {
+				t.Fatalf("retryDelay(1s, %d) = %v, want within [%v, %v)", attempt, got, d/2, d/2+d)
+			}
+		}
+	}
+	h := http.Header{"Retry-After": []string{"7"}}
+	if got := retryDelay(time.Second, 1, &h); got != 7*time.Second {
+		t.Errorf("retryDelay with Retry-After: 7 = %v, want 7s", got)
+	}
+	h.Set("Retry-After", "86400")
+	if got := retryDelay(time.Second, 1, &h); got != maxRetryDelay {
+		t.Errorf("retryDelay with a day's Retry-After = %v, want the %v cap", got, maxRetryDelay)
+	}
+}
@@ -0,0 +1,125 @@
+package scraper
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+// robotsAgent is the user-agent token matched against robots.txt groups
+// when no --user-agent is given. Requests then use a random browser user
+// agent, so the crawler identifies itself here instead; sites without a
+// "scraped" group fall back to "*".
+const robotsAgent = "scraped"
+
+// robotsCache fetches and caches robots.txt once per scheme+host.
+type robotsCache struct {
+	client  *http.Client
+	agent   string // token matched against User-agent lines
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	once sync.Once
+	data *robotstxt.RobotsData
+}
+
+// newRobotsCache matches robots.txt groups against the product token of
+// userAgent, such as "docs-bot" for "docs-bot/1.0 (ops@example.com)", or
+// against robotsAgent when userAgent is empty.
+func newRobotsCache(transport http.RoundTripper, timeout time.Duration, userAgent string) *robotsCache {
+	return &robotsCache{
+		client:  &http.Client{Transport: transport, Timeout: timeout},
+		agent:   cmp.Or(productToken(userAgent), robotsAgent),
+		entries: make(map[string]*robotsEntry),
+	}
+}
+
+// productToken returns the leading product name of a User-Agent header.
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), " ")
+	token, _, _ = strings.Cut(token, "/")
+	return token
+}
+
+// get returns the parsed robots.txt for u's host, fetching it on first use.
+// Concurrent callers for the same host share a single fetch. A nil result
+// means the file could not be retrieved and everything is allowed.
+func (rc *robotsCache) get(ctx context.Context, u *url.URL) *robotstxt.RobotsData {
+	key := u.Scheme + "://" + u.Host
+
+	rc.mu.Lock()
+	e, ok := rc.entries[key]
+	if !ok {
+		e = &robotsEntry{}
+		rc.entries[key] = e
+	}
+	rc.mu.Unlock()
+
+	e.once.Do(func() {
+		e.data = rc.fetch(ctx, key+"/robots.txt")
+	})
+	return e.data
+}
+
+func (rc *robotsCache) fetch(ctx context.Context, robotsURL string) *robotstxt.RobotsData {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("User-Agent", robotsAgent)
+
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 512<<10))
+	if err != nil {
+		return nil
+	}
+	// FromStatusAndBytes treats 4xx as allow-all and 5xx as disallow-all.
+	data, err := robotstxt.FromStatusAndBytes(resp.StatusCode, body)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// allowed reports whether robots.txt permits fetching u.
+func (rc *robotsCache) allowed(ctx context.Context, u *url.URL) bool {
+	data := rc.get(ctx, u)
+	if data == nil {
+		return true
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return data.TestAgent(path, rc.agent)
+}
+
+// crawlDelay returns the Crawl-delay robots.txt declares for u's host,
+// or 0 when none is set.
+func (rc *robotsCache) crawlDelay(ctx context.Context, u *url.URL) time.Duration {
+	data := rc.get(ctx, u)
+	if data == nil {
+		return 0
+	}
+	if g := data.FindGroup(rc.agent); g != nil {
+		return g.CrawlDelay
+	}
+	return 0
+}
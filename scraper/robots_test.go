@@ -0,0 +1,117 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRobotsCache(t *testing.T) {
+	const robots = `User-agent: *
+Disallow: /private
+Crawl-delay: 2
+
+User-agent: scraped
+Disallow: /drafts
+Allow: /drafts/public
+Crawl-delay: 1
+`
+	var fetches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fetches++
+		w.Write([]byte(robots))
+	}))
+	defer srv.Close()
+
+	rc := newRobotsCache(http.DefaultTransport, 5*time.Second, "")
+	ctx := context.Background()
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		// The "scraped" group replaces "*" entirely.
+		{"/private/page", true},
+		{"/drafts/post", false},
+		{"/drafts/public/post", true},
+		{"/drafts?id=1", false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(srv.URL + tt.path)
+		if got := rc.allowed(ctx, u); got != tt.want {
+			t.Errorf("allowed(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	u, _ := url.Parse(srv.URL + "/")
+	if got := rc.crawlDelay(ctx, u); got != time.Second {
+		t.Errorf("crawlDelay = %v, want 1s", got)
+	}
+	if fetches != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", fetches)
+	}
+}
+
+func TestRobotsCacheStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusNotFound, true},
+		{http.StatusForbidden, true},
+		{http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		rc := newRobotsCache(http.DefaultTransport, 5*time.Second, "")
+		u, _ := url.Parse(srv.URL + "/page")
+		if got := rc.allowed(context.Background(), u); got != tt.want {
+			t.Errorf("robots.txt status %d: allowed = %v, want %v", tt.status, got, tt.want)
+		}
+		srv.Close()
+	}
+}
+
+func TestRobotsCacheUserAgent(t *testing.T) {
+	const robots = `User-agent: *
+Disallow: /private
+
+User-agent: scraped
+Disallow: /drafts
+
+User-agent: docs-bot
+Disallow: /internal
+`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(robots))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		userAgent string
+		blocked   string
+	}{
+		{"", "/drafts"},
+		{"docs-bot/1.0 (ops@example.com)", "/internal"},
+		{"docs-bot (ops@example.com)", "/internal"},
+		// No group of its own: the "*" group applies.
+		{"Mozilla/5.0 (X11; Linux x86_64)", "/private"},
+	}
+	for _, tt := range tests {
+		rc := newRobotsCache(http.DefaultTransport, 5*time.Second, tt.userAgent)
+		for _, path := range []string{"/drafts", "/internal", "/private"} {
+			u, _ := url.Parse(srv.URL + path)
+			if got, want := rc.allowed(context.Background(), u), path != tt.blocked; got != want {
+				t.Errorf("user agent %q: allowed(%s) = %v, want %v", tt.userAgent, path, got, want)
+			}
+		}
+	}
+}
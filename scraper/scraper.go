@@ -1,13 +1,18 @@
 package scraper
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/gocolly/colly/v2"
 	"github.com/gocolly/colly/v2/extensions"
 	"github.com/yuin/goldmark"
@@ -22,8 +27,33 @@ import (
 var mdParser = goldmark.New()
 
 // extractMarkdownLinks parses markdown with goldmark and extracts all link
-// destinations (inline links, reference links, autolinks).
+// destinations (inline links, reference links, autolinks), canonicalized
+// for crawling.
 func extractMarkdownLinks(md string, baseURL string) []string {
+	self := CanonicalURL(baseURL)
+	var links []string
+	for _, l := range markdownLinks(md, baseURL) {
+		if !strings.HasPrefix(l.url, "http://") && !strings.HasPrefix(l.url, "https://") {
+			continue
+		}
+		// Same-page anchors point back at the page itself.
+		if link := CanonicalURL(l.url); link != self {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// pageLink is a link found on a page, resolved against it with any
+// fragment kept.
+type pageLink struct {
+	url  string
+	text string
+}
+
+// markdownLinks returns every link in md with its text, skipping mailto:
+// links.
+func markdownLinks(md string, baseURL string) []pageLink {
 	base, err := url.Parse(baseURL)
 	if err != nil {
 		return nil
@@ -32,7 +62,7 @@ func extractMarkdownLinks(md string, baseURL string) []string {
 	source := []byte(md)
 	doc := mdParser.Parser().Parse(text.NewReader(source))
 
-	var links []string
+	var links []pageLink
 	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
 			return ast.WalkContinue, nil
@@ -51,7 +81,7 @@ func extractMarkdownLinks(md string, baseURL string) []string {
 		}
 
 		href := strings.TrimSpace(string(dest))
-		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "mailto:") {
+		if href == "" || strings.HasPrefix(href, "mailto:") {
 			return ast.WalkContinue, nil
 		}
 
@@ -60,34 +90,99 @@ func extractMarkdownLinks(md string, baseURL string) []string {
 			return ast.WalkContinue, nil
 		}
 
-		resolved := base.ResolveReference(u)
-		resolved.Fragment = ""
-		if resolved.Scheme == "http" || resolved.Scheme == "https" {
-			links = append(links, resolved.String())
-		}
-
+		links = append(links, pageLink{
+			url:  base.ResolveReference(u).String(),
+			text: nodeText(n, source),
+		})
 		return ast.WalkContinue, nil
 	})
 
 	return links
 }
 
+// nodeText returns the plain text inside a markdown node.
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := c.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.CodeSpan:
+			for cc := t.FirstChild(); cc != nil; cc = cc.NextSibling() {
+				if tt, ok := cc.(*ast.Text); ok {
+					b.Write(tt.Segment.Value(source))
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink:
+			b.Write(t.Label(source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
 // Event is emitted during scraping for progress tracking.
 type Event struct {
-	Type   string // "fetching", "done", "error"
-	URL    string
-	Source string // "native" or "converted" (only for "done" events)
-	Err    error  // only for "error" events
+	Type    string // "fetching", "done", "duplicate", "error", "blocked", "filtered", "retrying", "throttled"
+	URL     string
+	Source  string        // "native", "sibling" or "converted" (only for "done" events)
+	Of      string        // URL of the page kept instead (only for "duplicate" events)
+	Err     error         // only for "error" and "retrying" events
+	Attempt int           // retry number, starting at 1 (only for "retrying" events)
+	Delay   time.Duration // wait before the retry, or the host's new delay for "throttled" events
 }
 
 // Options configures the scraper engine.
 type Options struct {
-	URLs         []string
-	Depth        int
-	Parallelism  int
-	MaxPages     int         // 0 = unlimited
-	CrossDomains bool        // allow crawling across different domains
-	OnEvent      func(Event) // optional progress callback
+	URLs            []string
+	Depth           int
+	Parallelism     int           // concurrent requests across all hosts
+	HostParallelism int           // concurrent requests to any one host, 0 = 8
+	Delay           time.Duration // minimum time between request starts on one host
+	RandomDelay     time.Duration // up to this much extra per-host delay, chosen at random
+	MaxPages        int           // 0 = unlimited
+	CrossDomains    bool          // allow crawling across different domains
+	IgnoreRobots    bool          // skip robots.txt checks and Crawl-delay when crawling
+	Sitemap         bool          // seed the crawl with URLs from the seed hosts' sitemaps
+	Include         []string      // discovered links must match one of these (regex or "glob:")
+	Exclude         []string      // discovered links matching any of these are skipped
+	PathPrefix      string        // discovered links must have a path starting with this
+	Retries         int           // retries for transient failures (5xx, 429, timeouts)
+	RetryBackoff    time.Duration // base delay for exponential retry backoff
+	CacheDir        string        // on-disk HTTP cache with conditional revalidation
+	Offline         bool          // serve only from CacheDir, never touching the network
+	StateFile       string        // journal crawl progress here so it can be resumed
+	Resume          bool          // continue the crawl recorded in StateFile
+	Readability     bool          // keep only the main article content of HTML pages
+	Selector        string        // CSS selector: convert only matching elements
+	ExcludeSelector string        // CSS selector: drop matching elements before conversion
+	AssetDir        string        // download images pages reference into this directory
+	AssetDomains    []string      // extra hosts (and subdomains) images may be downloaded from
+	MaxAssetSize    int64         // bytes per downloaded asset, 0 = 10 MiB
+	DiscoverMD      bool          // prefer markdown the site publishes: /llms.txt links and page.md siblings
+	Dedupe          string        // also collapse pages by content: "exact" or "similar" (simhash)
+	UserAgent       string        // sent instead of a random browser user agent
+	Headers         []string      // "Name: value", or "domain=Name: value" to send beyond the seed hosts
+	Cookies         []string      // Set-Cookie syntax; without a Domain attribute, sent to the seed hosts only
+	CookieJar       string        // Netscape cookies.txt to load before the crawl and save after it
+	Auth            []string      // per-host credentials: "host=basic:user:password" or "host=bearer:ENV_VAR"
+	Netrc           bool          // also look up credentials in $NETRC or ~/.netrc
+	Proxies         []string      // proxy URLs, rotated across hosts; "domain=URL" routes one domain
+	CACert          string        // PEM file of extra CA certificates to trust
+	ClientCert      string        // PEM client certificate for mutual TLS
+	ClientKey       string        // PEM key for ClientCert, if not in the same file
+	Insecure        bool          // skip server certificate verification
+	OnEvent         func(Event)   // optional progress callback
+	OnResult        func(Result)  // optional: stream results here as they finish instead of returning them
+
+	hooks *crawlHooks // set by LinkChecker to observe pages and links
 }
 
 func (o *Options) emit(e Event) {
@@ -128,18 +223,186 @@ func extractDomains(urls []string) []string {
 	return domains
 }
 
-func cleanLink(raw string) string {
-	u, err := url.Parse(raw)
-	if err != nil {
-		return raw
+// fetchInfo follows a request from OnRequest to its response.
+type fetchInfo struct {
+	url     string // as originally requested, before redirects
+	start   time.Time
+	release func() // frees the request's per-host slot
+}
+
+func (fi fetchInfo) done() {
+	if fi.release != nil {
+		fi.release()
 	}
-	u.Fragment = ""
-	return u.String()
 }
 
-// Run scrapes all seed URLs and returns the collected results.
+// Run scrapes all seed URLs and returns the collected results. When
+// opts.OnResult is set, each result is passed to it as soon as it is ready,
+// one call at a time, and Run keeps and returns none of them.
 func Run(ctx context.Context, opts Options) ([]Result, error) {
 	store := NewResultStore()
+	store.discard = opts.OnResult != nil
+	takeURLCredentials(&opts)
+
+	dedup, err := newDedupIndex(opts.Dedupe)
+	if err != nil {
+		return nil, err
+	}
+
+	// A resumed crawl takes its scope from the state file and starts with
+	// the results it already finished.
+	var (
+		journal *stateJournal
+		state   *crawlState
+	)
+	switch {
+	case opts.Resume:
+		journal, state, err = resumeStateJournal(opts.StateFile)
+		if err != nil {
+			return nil, err
+		}
+		state.scope.apply(&opts)
+		if dedup, err = newDedupIndex(opts.Dedupe); err != nil {
+			return nil, err
+		}
+		for _, r := range state.results {
+			dedup.check(r)
+			store.Add(r)
+		}
+	case opts.StateFile != "":
+		journal, err = createStateJournal(opts.StateFile, scopeOf(opts))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if journal != nil {
+		defer journal.Close()
+	}
+
+	// Seeds scope unscoped credentials, so the session is built from the
+	// seeds a resumed crawl recorded, before sitemaps and llms.txt add more.
+	sess, err := newSession(opts)
+	if err != nil {
+		return nil, err
+	}
+	// Credentials never leave through events or results.
+	if next := opts.OnEvent; next != nil {
+		opts.OnEvent = func(e Event) { next(sess.auth.redactEvent(e)) }
+	}
+
+	// deliver hands a result to OnResult. Callers hold deliverMu, so
+	// OnResult runs one call at a time and can write to a shared stream
+	// without locking.
+	var deliverMu sync.Mutex
+	deliver := func(r Result) {
+		if opts.OnResult != nil {
+			opts.OnResult(r)
+		}
+	}
+
+	// record stores a finished result and journals it for --resume. A page
+	// that duplicates one already stored becomes an alias of it instead,
+	// and record reports false after emitting a "duplicate" event. Holding
+	// deliverMu throughout means that event always follows the delivery of
+	// the page it points at, so streams can refer back to it.
+	record := func(r Result) bool {
+		deliverMu.Lock()
+		defer deliverMu.Unlock()
+		r = sess.auth.redactResult(r)
+		if kept, dup := dedup.check(r); dup {
+			store.AddAlias(kept, r.URL)
+			if journal != nil {
+				journal.alias(r.URL, kept)
+			}
+			opts.emit(Event{Type: "duplicate", URL: r.URL, Of: kept})
+			return false
+		}
+		if !store.Add(r) {
+			return true
+		}
+		if journal != nil {
+			journal.done(r)
+		}
+		deliver(r)
+		return true
+	}
+
+	filter, err := newURLFilter(opts.Include, opts.Exclude, opts.PathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := newContentScope(opts.Selector, opts.ExcludeSelector, opts.Readability)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := newHostLimiter(opts.HostParallelism, opts.Delay, opts.RandomDelay)
+	limiter.onSlow = func(u *url.URL, delay time.Duration) {
+		opts.emit(Event{Type: "throttled", URL: u.String(), Delay: delay})
+	}
+	transport, err := buildTransport(opts, limiter, sess)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sitemap mode is a crawl even at depth 0: pages come from the seed
+	// hosts' sitemaps rather than from links.
+	crawling := opts.Depth > 0 || opts.Sitemap
+	robots := newRobotsCache(transport, 15*time.Second, opts.UserAgent)
+
+	var allowedDomains []string
+	if crawling && !opts.CrossDomains {
+		allowedDomains = extractDomains(opts.URLs)
+	}
+
+	// keep applies the crawl filters to a discovered link, reporting each
+	// rejected URL once.
+	var reported sync.Map
+	keep := func(link string) bool {
+		if filter.allow(link) {
+			return true
+		}
+		if _, dup := reported.LoadOrStore(link, true); !dup {
+			opts.emit(Event{Type: "filtered", URL: link})
+		}
+		return false
+	}
+
+	// The frontier of a resumed crawl already holds the sitemap's pages.
+	if opts.Sitemap && state == nil {
+		opts.URLs = expandSitemaps(ctx, robots, opts.URLs, allowedDomains, keep, opts.MaxPages)
+	}
+
+	var prober *mdProber
+	if opts.DiscoverMD {
+		var allow func(*url.URL) bool
+		if crawling && !opts.IgnoreRobots {
+			allow = func(u *url.URL) bool { return robots.allowed(ctx, u) }
+		}
+		prober = newMDProber(transport, 15*time.Second, allow)
+		if crawling && state == nil {
+			opts.URLs = expandLLMsTxt(ctx, prober, opts.URLs, allowedDomains, keep, opts.MaxPages)
+		}
+	}
+
+	// follow queues a link discovered on r's page. Links colly would reject
+	// anyway (too deep, off-domain) are dropped before filtering so they are
+	// not reported as filtered.
+	follow := func(r *colly.Request, link string) {
+		if r.Depth > opts.Depth || (state != nil && state.done[link]) {
+			return
+		}
+		if len(allowedDomains) > 0 {
+			u, err := url.Parse(link)
+			if err != nil || !slices.Contains(allowedDomains, u.Hostname()) {
+				return
+			}
+		}
+		if keep(link) {
+			_ = r.Visit(link)
+		}
+	}
 
 	// Colly depth model: c.Visit() starts at depth 1, children are depth 2, etc.
 	// MaxDepth(N) rejects depth > N. So --depth 0 (seeds only) = MaxDepth(1),
@@ -149,117 +412,340 @@ func Run(ctx context.Context, opts Options) ([]Result, error) {
 		colly.Async(),
 	}
 
-	// When crawling (depth > 0), restrict to seed URL domains unless --cross-domains.
-	if opts.Depth > 0 && !opts.CrossDomains {
-		domains := extractDomains(opts.URLs)
-		if len(domains) > 0 {
-			collectorOpts = append(collectorOpts, colly.AllowedDomains(domains...))
-		}
+	// When crawling, restrict to seed URL domains unless --cross-domains.
+	if len(allowedDomains) > 0 {
+		collectorOpts = append(collectorOpts, colly.AllowedDomains(allowedDomains...))
 	}
 
 	c := colly.NewCollector(collectorOpts...)
+	c.WithTransport(transport)
+	// Cookies are handled by the session, for every fetcher alike.
+	c.DisableCookies()
 
-	extensions.RandomUserAgent(c)
+	if opts.UserAgent == "" {
+		extensions.RandomUserAgent(c)
+	}
 	extensions.Referer(c)
 
 	c.SetRequestTimeout(15 * time.Second)
 
+	// robots.txt is honored only when crawling; single-page scrapes fetch
+	// exactly what was asked for.
+	respectRobots := crawling && !opts.IgnoreRobots
+
+	// Images are downloaded by the worker that converted the page, under
+	// the same domain and robots.txt rules as pages.
+	var assets *assetFetcher
+	if opts.AssetDir != "" {
+		allow := assetHostAllowed(opts.URLs, opts.AssetDomains, opts.CrossDomains)
+		if respectRobots {
+			hostOK := allow
+			allow = func(u *url.URL) bool {
+				return hostOK(u) && robots.allowed(ctx, u)
+			}
+		}
+		assets, err = newAssetFetcher(transport, opts.AssetDir, opts.MaxAssetSize, allow)
+		if err != nil {
+			return nil, err
+		}
+	}
+	localAssets := func(md, base string) map[string]string {
+		if assets == nil {
+			return nil
+		}
+		return assets.fetchAll(ctx, md, base)
+	}
+
+	// One wildcard rule is a single pool shared by every host, so colly
+	// enforces the global limit; limiter handles each host's share and
+	// spacing in OnRequest, before a global slot is taken.
 	_ = c.Limit(&colly.LimitRule{
 		DomainGlob:  "*",
 		Parallelism: opts.Parallelism,
 	})
 
-	var started atomic.Int64
+	var (
+		started atomic.Int64
+		retries retryCounts
+		fetches sync.Map // request ID → fetchInfo
+	)
+
+	// takeFetch returns what OnRequest recorded for r. Entries are keyed by
+	// request ID because r.URL changes on redirect.
+	takeFetch := func(r *colly.Request) fetchInfo {
+		v, _ := fetches.LoadAndDelete(r.ID)
+		if fi, ok := v.(fetchInfo); ok {
+			return fi
+		}
+		return fetchInfo{url: r.URL.String(), start: time.Now()}
+	}
+
+	// Pages finished before an interruption count towards --max-pages, and
+	// frontier URLs get their original depth back when re-requested.
+	resumeDepth := make(map[string]int)
+	if state != nil {
+		started.Store(int64(len(state.results)))
+		for _, p := range state.pending {
+			resumeDepth[p.URL] = p.Depth
+		}
+	}
 
 	c.OnRequest(func(r *colly.Request) {
 		if ctx.Err() != nil {
 			r.Abort()
 			return
 		}
-		if opts.MaxPages > 0 && int(started.Load()) >= opts.MaxPages {
+		if respectRobots && !robots.allowed(ctx, r.URL) {
+			r.Abort()
+			opts.emit(Event{Type: "blocked", URL: r.URL.String()})
+			return
+		}
+		// Retries were already counted and announced on their first attempt.
+		attempt, origin := retries.get(r.URL.String())
+		retry := attempt > 0
+		if !retry {
+			// Reserve the page before waiting for a host slot, so requests
+			// queued behind the limiter cannot overshoot --max-pages.
+			if n := started.Add(1); opts.MaxPages > 0 && int(n) > opts.MaxPages {
+				started.Add(-1)
+				r.Abort()
+				return
+			}
+			if d, ok := resumeDepth[r.URL.String()]; ok {
+				r.Depth = d
+			}
+			// Journaled before the wait, so pages still waiting at an
+			// interruption are resumed.
+			if journal != nil {
+				journal.queued(r.URL.String(), r.Depth)
+			}
+		}
+		var crawlDelay time.Duration
+		if respectRobots {
+			crawlDelay = robots.crawlDelay(ctx, r.URL)
+		}
+		start := time.Now()
+		release, ok := limiter.acquire(ctx, r.URL.Host, crawlDelay)
+		if !ok {
+			if !retry {
+				started.Add(-1)
+			}
 			r.Abort()
 			return
 		}
-		started.Add(1)
 		r.Headers.Set("Accept", "text/markdown")
-		opts.emit(Event{Type: "fetching", URL: r.URL.String()})
+		if auth := sess.auth.authorization(r.URL); auth != "" {
+			r.Headers.Set("Authorization", auth)
+		}
+		if !retry {
+			opts.emit(Event{Type: "fetching", URL: r.URL.String()})
+		}
+		fetches.Store(r.ID, fetchInfo{url: origin, start: start, release: release})
+
+		// A published .md twin replaces the HTML fetch entirely.
+		if prober == nil || retry {
+			return
+		}
+		v, ok := prober.sibling(ctx, r.URL)
+		if !ok {
+			return
+		}
+		fi := takeFetch(r)
+		fi.done()
+		meta := markdownMetadata(v.body, &v.headers)
+		meta.FinalURL = v.url
+		meta.StatusCode = v.status
+		meta.ContentType = v.ctype
+		meta.Size = len(v.body)
+		meta.Duration = time.Since(fi.start)
+		if record(Result{
+			URL:      fi.url,
+			Markdown: v.body,
+			Source:   "sibling",
+			Meta:     meta,
+			Assets:   localAssets(v.body, v.url),
+		}) {
+			opts.emit(Event{Type: "done", URL: fi.url, Source: "sibling"})
+		}
+		opts.hooks.page(fi.url, v.url, v.status, nil)
+		opts.hooks.markdownLinks(v.body, v.url)
+		if opts.Depth > 0 {
+			for _, link := range extractMarkdownLinks(v.body, v.url) {
+				follow(r, link)
+			}
+		}
+		r.Abort()
 	})
 
 	c.OnResponse(func(r *colly.Response) {
+		fi := takeFetch(r.Request)
+		fi.done()
 		ct := r.Headers.Get("Content-Type")
+		// reqURL is where the page ended up; links resolve against it.
 		reqURL := r.Request.URL.String()
 
+		withResponse := func(m Metadata) Metadata {
+			m.FinalURL = reqURL
+			m.StatusCode = r.StatusCode
+			m.ContentType = ct
+			m.Size = len(r.Body)
+			m.Duration = time.Since(fi.start)
+			return m
+		}
+		opts.hooks.page(fi.url, reqURL, r.StatusCode, nil)
+
 		switch {
-		case strings.Contains(ct, "text/markdown"):
+		case strings.Contains(ct, "text/markdown"), prober != nil && isMarkdownType(ct, r.Request.URL):
 			body := string(r.Body)
-			store.Add(Result{
-				URL:      reqURL,
+			if record(Result{
+				URL:      fi.url,
 				Markdown: body,
 				Source:   "native",
-			})
-			opts.emit(Event{Type: "done", URL: reqURL, Source: "native"})
+				Meta:     withResponse(markdownMetadata(body, r.Headers)),
+				Assets:   localAssets(body, reqURL),
+			}) {
+				opts.emit(Event{Type: "done", URL: fi.url, Source: "native"})
+			}
+			opts.hooks.markdownLinks(body, reqURL)
 			// Native markdown has no HTML DOM for colly to parse.
 			// Extract links from the markdown AST and queue them.
 			if opts.Depth > 0 {
 				for _, link := range extractMarkdownLinks(body, reqURL) {
-					_ = r.Request.Visit(link)
+					follow(r.Request, link)
 				}
 			}
 
 		case strings.Contains(ct, "text/html"):
+			page := string(r.Body)
+			var meta Metadata
+			if doc, err := goquery.NewDocumentFromReader(bytes.NewReader(r.Body)); err == nil {
+				meta = htmlMetadata(doc, r.Request.AbsoluteURL, r.Headers)
+				opts.hooks.htmlAnchors(reqURL, doc)
+				if scope.active() {
+					page = scope.apply(doc)
+				}
+			}
+			meta = withResponse(meta)
 			md, err := htmltomarkdown.ConvertString(
-				string(r.Body),
+				page,
 				converter.WithDomain(reqURL),
 			)
 			if err != nil {
-				store.Add(Result{
-					URL: reqURL,
-					Err: fmt.Errorf("markdown conversion failed: %w", err),
+				record(Result{
+					URL:  fi.url,
+					Err:  fmt.Errorf("markdown conversion failed: %w", err),
+					Meta: meta,
 				})
-				opts.emit(Event{Type: "error", URL: reqURL, Err: err})
+				opts.emit(Event{Type: "error", URL: fi.url, Err: err})
 				return
 			}
-			store.Add(Result{
-				URL:      reqURL,
+			if record(Result{
+				URL:      fi.url,
 				Markdown: md,
 				Source:   "converted",
-			})
-			opts.emit(Event{Type: "done", URL: reqURL, Source: "converted"})
+				Meta:     meta,
+				Assets:   localAssets(md, reqURL),
+			}) {
+				opts.emit(Event{Type: "done", URL: fi.url, Source: "converted"})
+			}
 
 		default:
 			// Non-HTML/markdown (CSS, JS, images, etc.) — emit so TUI can clean up.
-			opts.emit(Event{Type: "done", URL: reqURL, Source: "skipped"})
+			opts.emit(Event{Type: "done", URL: fi.url, Source: "skipped"})
 		}
 	})
 
-	if opts.Depth > 0 {
+	if opts.Depth > 0 || opts.hooks != nil {
 		c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+			opts.hooks.htmlLink(e)
 			link := e.Request.AbsoluteURL(e.Attr("href"))
 			if link == "" {
 				return
 			}
-			_ = e.Request.Visit(cleanLink(link))
+			follow(e.Request, CanonicalURL(link))
 		})
 	}
 
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
 	c.OnError(func(r *colly.Response, err error) {
+		fi := takeFetch(r.Request)
+		fi.done()
 		// Silently ignore aborted requests (context cancellation or max-pages).
-		if ctx.Err() != nil || r.StatusCode == 0 {
+		if ctx.Err() != nil || isAborted(err) {
 			return
 		}
 		reqURL := r.Request.URL.String()
-		store.Add(Result{
-			URL: reqURL,
-			Err: fmt.Errorf("request failed (status %d): %w", r.StatusCode, err),
+		err = classifyTLS(err)
+
+		// Holding this worker while backing off keeps c.Wait() from
+		// returning before the retry is queued.
+		if attempt, _ := retries.get(reqURL); attempt < opts.Retries && isTransient(r.StatusCode, err) {
+			attempt++
+			delay := retryDelay(backoff, attempt, r.Headers)
+			opts.emit(Event{Type: "retrying", URL: fi.url, Err: err, Attempt: attempt, Delay: delay})
+			if !sleepCtx(ctx, delay) {
+				return
+			}
+			retries.set(reqURL, attempt, fi.url)
+			if r.Request.Retry() == nil {
+				return
+			}
+		}
+
+		// TLS failures never got a status; their category says more.
+		resultErr := err
+		if !errors.Is(err, ErrTLS) {
+			resultErr = fmt.Errorf("request failed (status %d): %w", r.StatusCode, err)
+		}
+		record(Result{
+			URL: fi.url,
+			Err: resultErr,
+			Meta: Metadata{
+				FinalURL:   reqURL,
+				StatusCode: r.StatusCode,
+				Duration:   time.Since(fi.start),
+			},
 		})
-		opts.emit(Event{Type: "error", URL: reqURL, Err: err})
+		opts.emit(Event{Type: "error", URL: fi.url, Err: err})
+		opts.hooks.page(fi.url, reqURL, r.StatusCode, err)
 	})
 
-	for _, u := range opts.URLs {
-		_ = c.Visit(u)
+	if state != nil {
+		deliverMu.Lock()
+		for _, r := range state.results {
+			deliver(r)
+		}
+		deliverMu.Unlock()
+		state.results = nil
+		// Seeds missing from the journal never got as far as a request.
+		for _, u := range opts.URLs {
+			if _, ok := resumeDepth[u]; !ok && !state.done[u] {
+				_ = c.Visit(u)
+			}
+		}
+		for _, p := range state.pending {
+			_ = c.Visit(p.URL)
+		}
+	} else {
+		for _, u := range opts.URLs {
+			_ = c.Visit(u)
+		}
 	}
 
 	c.Wait()
 
+	if err := sess.save(); err != nil {
+		return store.Results(), fmt.Errorf("failed to save cookie jar: %w", err)
+	}
+	if journal != nil {
+		if err := journal.Close(); err != nil {
+			return store.Results(), fmt.Errorf("failed to write state file: %w", err)
+		}
+	}
+
 	return store.Results(), nil
 }
@@ -0,0 +1,228 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSite serves an index page linking to n pages, each with a little text.
+func newSite(t *testing.T, n int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Path == "/robots.txt" || r.URL.Path == "/sitemap.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "<html><head><title>%s</title></head><body><h1>Page %s</h1><p>Some text.</p>", r.URL.Path, r.URL.Path)
+		if r.URL.Path == "/" {
+			for i := range n {
+				fmt.Fprintf(&b, `<a href="/page/%d">page %d</a> `, i, i)
+			}
+		}
+		b.WriteString("</body></html>")
+		fmt.Fprint(w, b.String())
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRunFollowsLinks(t *testing.T) {
+	srv := newSite(t, 5)
+	results, err := Run(context.Background(), Options{
+		URLs:        []string{srv.URL + "/"},
+		Depth:       1,
+		Parallelism: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("got %d results, want 6", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.URL, r.Err)
+		}
+		if !strings.Contains(r.Markdown, "Some text.") {
+			t.Errorf("%s: markdown %q is missing the page text", r.URL, r.Markdown)
+		}
+	}
+}
+
+func TestRunMaxPages(t *testing.T) {
+	// One request per host at a time leaves most links waiting in the
+	// limiter, which must not let them past the page limit.
+	srv := newSite(t, 20)
+	var fetched atomic.Int64
+	results, err := Run(context.Background(), Options{
+		URLs:            []string{srv.URL + "/"},
+		Depth:           1,
+		Parallelism:     8,
+		HostParallelism: 1,
+		MaxPages:        3,
+		OnEvent: func(e Event) {
+			if e.Type == "fetching" {
+				fetched.Add(1)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Errorf("got %d results, want 3", len(results))
+	}
+	if n := fetched.Load(); n != 3 {
+		t.Errorf("fetched %d pages, want 3", n)
+	}
+}
+
+func TestRunResumeKeepsWaitingLinks(t *testing.T) {
+	// Links still waiting for a host slot when the crawl is interrupted
+	// must be in the journal, or the resumed crawl never fetches them.
+	srv := newSite(t, 5)
+	opts := Options{
+		URLs:            []string{srv.URL + "/"},
+		Depth:           1,
+		Parallelism:     4,
+		HostParallelism: 1,
+		Delay:           50 * time.Millisecond,
+		StateFile:       filepath.Join(t.TempDir(), "state.jsonl"),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var done atomic.Int64
+	first := opts
+	first.OnEvent = func(e Event) {
+		// Stop once the index and one linked page are in.
+		if e.Type == "done" && done.Add(1) == 2 {
+			cancel()
+		}
+	}
+	if _, err := Run(ctx, first); err != nil && !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+
+	opts.Resume = true
+	results, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 6 {
+		t.Errorf("got %d results after resuming, want 6", len(results))
+	}
+}
+
+func TestRunStreamsDuplicates(t *testing.T) {
+	// /a and /b serve the same page. Whichever comes second is a
+	// duplicate, reported only after the first was handed to OnResult.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><body><h1>Index</h1><a href="/a">a</a> <a href="/b">b</a></body></html>`)
+		case "/a", "/b":
+			fmt.Fprint(w, `<html><body><h1>Same</h1><p>Identical text.</p></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	var (
+		mu        sync.Mutex
+		delivered []string
+		dups      int
+	)
+	_, err := Run(context.Background(), Options{
+		URLs:        []string{srv.URL + "/"},
+		Depth:       1,
+		Parallelism: 4,
+		Dedupe:      DedupeExact,
+		OnResult: func(r Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, r.URL)
+		},
+		OnEvent: func(e Event) {
+			if e.Type != "duplicate" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			dups++
+			if !slices.Contains(delivered, e.Of) {
+				t.Errorf("duplicate %s of %s reported before that page was delivered", e.URL, e.Of)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 2 || dups != 1 {
+		t.Errorf("delivered %q with %d duplicates, want 2 pages and 1 duplicate", delivered, dups)
+	}
+}
+
+func TestRunResumeRetriesFailures(t *testing.T) {
+	// /page/1 fails during the first run; resuming must fetch it again
+	// rather than replay the failure.
+	var broken atomic.Bool
+	broken.Store(true)
+	site := newSite(t, 3)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page/1" && broken.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		site.Config.Handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	opts := Options{
+		URLs:        []string{srv.URL + "/"},
+		Depth:       1,
+		Parallelism: 2,
+		StateFile:   filepath.Join(t.TempDir(), "state.jsonl"),
+	}
+	first, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := 0
+	for _, r := range first {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("first run had %d failures, want 1", failed)
+	}
+
+	broken.Store(false)
+	opts.Resume = true
+	results, err := Run(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Errorf("got %d results after resuming, want 4", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.URL, r.Err)
+		}
+	}
+}
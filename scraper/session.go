@@ -0,0 +1,217 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// session holds what every request carries besides its URL: the user
+// agent, extra headers, cookies and credentials. Headers and cookies given
+// without a domain go to the seed hosts only, so credentials meant for one
+// site are never sent to the other hosts a crawl touches.
+type session struct {
+	userAgent string
+	headers   []headerRule
+	seeds     []string // hosts unscoped headers are sent to
+	jar       *cookieJar
+	jarFile   string // Netscape cookies.txt to load and save, if any
+	auth      *authConfig
+}
+
+// headerRule is one --header value.
+type headerRule struct {
+	domain string // send to this domain and its subdomains; "" = seed hosts
+	name   string
+	value  string
+}
+
+func newSession(opts Options) (*session, error) {
+	s := &session{
+		userAgent: opts.UserAgent,
+		seeds:     extractDomains(opts.URLs),
+		jar:       newCookieJar(),
+		jarFile:   opts.CookieJar,
+	}
+	var err error
+	if s.auth, err = parseAuth(opts.Auth, opts.Netrc); err != nil {
+		return nil, err
+	}
+	for _, raw := range opts.Headers {
+		h, err := parseHeader(raw)
+		if err != nil {
+			return nil, err
+		}
+		s.headers = append(s.headers, h)
+		// Custom headers often carry API keys, so their values are
+		// redacted like credentials.
+		s.auth.secrets = append(s.auth.secrets, h.value)
+	}
+
+	if s.jarFile != "" {
+		f, err := os.Open(s.jarFile)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// Created on save.
+		case err != nil:
+			return nil, fmt.Errorf("failed to open cookie jar: %w", err)
+		default:
+			err = s.jar.load(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cookie jar %s: %w", s.jarFile, err)
+			}
+		}
+	}
+
+	// Cookies from the command line override those loaded from the jar.
+	for _, raw := range opts.Cookies {
+		if err := s.addCookie(raw); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseHeader parses "Name: value", optionally prefixed with "domain=" to
+// send the header to that domain and its subdomains.
+func parseHeader(raw string) (headerRule, error) {
+	name, value, ok := strings.Cut(raw, ":")
+	if !ok {
+		return headerRule{}, fmt.Errorf("invalid header %q: want \"Name: value\" or \"domain=Name: value\"", raw)
+	}
+	// Header names cannot contain "=", so one before the colon ends a domain.
+	var h headerRule
+	scoped := false
+	if domain, rest, ok := strings.Cut(name, "="); ok {
+		h.domain, name, scoped = normalizeDomain(domain), rest, true
+	}
+	h.name = strings.TrimSpace(name)
+	h.value = strings.TrimSpace(value)
+	if !httpguts.ValidHeaderFieldName(h.name) || scoped && h.domain == "" {
+		return headerRule{}, fmt.Errorf("invalid header %q: want \"Name: value\" or \"domain=Name: value\"", raw)
+	}
+	if !httpguts.ValidHeaderFieldValue(h.value) {
+		return headerRule{}, fmt.Errorf("invalid header %q: value contains control characters", raw)
+	}
+	return h, nil
+}
+
+// addCookie parses a cookie in Set-Cookie syntax, "name=value" with
+// optional attributes. With a Domain attribute it is sent to that domain
+// and its subdomains; without one, only to the seed hosts.
+func (s *session) addCookie(raw string) error {
+	hc, err := http.ParseSetCookie(raw)
+	if err != nil {
+		return fmt.Errorf("invalid cookie %q: %w", raw, err)
+	}
+	c := &jarCookie{
+		name:     hc.Name,
+		value:    hc.Value,
+		path:     hc.Path,
+		secure:   hc.Secure,
+		httpOnly: hc.HttpOnly,
+		expires:  hc.Expires,
+	}
+	if !strings.HasPrefix(c.path, "/") {
+		c.path = "/"
+	}
+	if d := normalizeDomain(hc.Domain); d != "" {
+		c.domain = d
+		s.jar.add(c)
+		return nil
+	}
+	if len(s.seeds) == 0 {
+		return fmt.Errorf("cookie %q has no Domain attribute and there is no seed URL to scope it to", raw)
+	}
+	for _, host := range s.seeds {
+		hostCookie := *c
+		hostCookie.domain, hostCookie.hostOnly = host, true
+		s.jar.add(&hostCookie)
+	}
+	return nil
+}
+
+func normalizeDomain(d string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "."))
+}
+
+// apply sets the session's user agent, and the headers, cookies and
+// credentials meant for req's host, on req. The collector sets
+// Authorization in OnRequest already; this covers the other fetchers and
+// redirects, which drop it when they leave the original host.
+func (s *session) apply(req *http.Request) {
+	host := strings.ToLower(req.URL.Hostname())
+	if s.userAgent != "" {
+		req.Header.Set("User-Agent", s.userAgent)
+	}
+	for _, h := range s.headers {
+		if h.domain == "" && slices.Contains(s.seeds, host) || h.domain != "" && domainMatch(host, h.domain) {
+			req.Header.Set(h.name, h.value)
+		}
+	}
+	for _, c := range s.jar.Cookies(req.URL) {
+		req.AddCookie(c)
+	}
+	if req.Header.Get("Authorization") == "" {
+		if v := s.auth.authorization(req.URL); v != "" {
+			req.Header.Set("Authorization", v)
+		}
+	}
+}
+
+// headerNames returns the names of the --header headers, which the cache
+// treats like credentials. It is safe to call on a nil session.
+func (s *session) headerNames() []string {
+	if s == nil {
+		return nil
+	}
+	var names []string
+	for _, h := range s.headers {
+		names = append(names, h.name)
+	}
+	return names
+}
+
+// save writes the cookie jar back to its file, including cookies sites
+// set during the crawl.
+func (s *session) save() error {
+	if s.jarFile == "" {
+		return nil
+	}
+	f, err := os.OpenFile(s.jarFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := s.jar.save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// sessionTransport applies a session to every request and stores the
+// cookies responses set. The client runs each redirect hop through it, so
+// credentials are matched against the host actually being contacted.
+type sessionTransport struct {
+	next    http.RoundTripper
+	session *session
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	t.session.apply(req)
+	resp, err := t.next.RoundTrip(req)
+	if err == nil {
+		if cookies := resp.Cookies(); len(cookies) > 0 {
+			t.session.jar.SetCookies(req.URL, cookies)
+		}
+	}
+	return resp, err
+}
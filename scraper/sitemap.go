@@ -0,0 +1,197 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSitemapBytes is the uncompressed size limit from the sitemaps protocol.
+	maxSitemapBytes = 50 << 20
+	// maxSitemapNesting bounds how deep sitemap indexes may reference
+	// further indexes, guarding against cycles and runaway generators.
+	maxSitemapNesting = 4
+	// sitemapTimeout bounds each sitemap fetch. It is longer than the
+	// robots.txt timeout since a sitemap may be up to maxSitemapBytes.
+	sitemapTimeout = 2 * time.Minute
+)
+
+// sitemapDoc covers both <urlset> and <sitemapindex> documents.
+type sitemapDoc struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// isSitemapURL reports whether a seed points at a sitemap file rather than a page.
+func isSitemapURL(u *url.URL) bool {
+	p := strings.ToLower(u.Path)
+	return strings.HasSuffix(p, ".xml") || strings.HasSuffix(p, ".xml.gz")
+}
+
+// sitemapExpander walks sitemaps and collects page URLs.
+type sitemapExpander struct {
+	client  *http.Client
+	allowed []string          // allowed hostnames, nil = any
+	keep    func(string) bool // crawl filter applied to sitemap entries
+	limit   int               // max page URLs to collect, 0 = unlimited
+
+	visited map[string]bool
+	seen    map[string]bool
+	pages   []string
+}
+
+// expandSitemaps returns the seeds followed by every page URL listed in the
+// seeds' sitemaps. Seeds that are themselves sitemap files are expanded
+// instead of being scraped; when there are none, sitemaps are discovered
+// from each seed host's robots.txt and /sitemap.xml. Pages outside allowed
+// or rejected by keep are dropped and at most limit URLs are returned when
+// limit > 0.
+func expandSitemaps(ctx context.Context, robots *robotsCache, seeds []string, allowed []string, keep func(string) bool, limit int) []string {
+	se := &sitemapExpander{
+		client:  &http.Client{Transport: robots.client.Transport, Timeout: sitemapTimeout},
+		allowed: allowed,
+		keep:    keep,
+		limit:   limit,
+		visited: make(map[string]bool),
+		seen:    make(map[string]bool),
+	}
+
+	// Sitemaps given explicitly replace discovery, so the crawl covers
+	// just the pages they list.
+	explicit := slices.ContainsFunc(seeds, func(raw string) bool {
+		u, err := url.Parse(raw)
+		return err == nil && isSitemapURL(u)
+	})
+
+	var sitemaps []string
+	hosts := make(map[string]bool)
+	for _, raw := range seeds {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		if isSitemapURL(u) {
+			sitemaps = append(sitemaps, raw)
+		} else {
+			se.add(raw, true)
+		}
+		origin := u.Scheme + "://" + u.Host
+		if explicit || hosts[origin] {
+			continue
+		}
+		hosts[origin] = true
+		if data := robots.get(ctx, u); data != nil {
+			sitemaps = append(sitemaps, data.Sitemaps...)
+		}
+		sitemaps = append(sitemaps, origin+"/sitemap.xml")
+	}
+
+	for _, sm := range sitemaps {
+		if se.full() || ctx.Err() != nil {
+			break
+		}
+		se.walk(ctx, sm, 0)
+	}
+	return se.pages
+}
+
+func (se *sitemapExpander) full() bool {
+	return se.limit > 0 && len(se.pages) >= se.limit
+}
+
+func (se *sitemapExpander) add(raw string, seed bool) {
+	if se.full() || se.seen[raw] {
+		return
+	}
+	if se.allowed != nil {
+		u, err := url.Parse(raw)
+		if err != nil || !slices.Contains(se.allowed, u.Hostname()) {
+			return
+		}
+	}
+	if !seed && se.keep != nil && !se.keep(raw) {
+		return
+	}
+	se.seen[raw] = true
+	se.pages = append(se.pages, raw)
+}
+
+func (se *sitemapExpander) walk(ctx context.Context, sitemapURL string, nesting int) {
+	if nesting > maxSitemapNesting || se.visited[sitemapURL] {
+		return
+	}
+	se.visited[sitemapURL] = true
+
+	doc, err := se.fetch(ctx, sitemapURL)
+	if err != nil {
+		return
+	}
+	for _, u := range doc.URLs {
+		loc := strings.TrimSpace(u.Loc)
+		if loc != "" {
+			se.add(CanonicalURL(loc), false)
+		}
+	}
+	for _, sm := range doc.Sitemaps {
+		if se.full() || ctx.Err() != nil {
+			return
+		}
+		if loc := strings.TrimSpace(sm.Loc); loc != "" {
+			se.walk(ctx, loc, nesting+1)
+		}
+	}
+}
+
+// fetch downloads and parses a sitemap, transparently handling gzip bodies
+// whether or not the server labels them with Content-Encoding.
+func (se *sitemapExpander) fetch(ctx context.Context, sitemapURL string) (*sitemapDoc, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", robotsAgent)
+
+	resp, err := se.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sitemap returned %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSitemapBytes))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		body, err = io.ReadAll(io.LimitReader(zr, maxSitemapBytes))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var doc sitemapDoc
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
@@ -0,0 +1,155 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsSitemapURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/sitemap.xml", true},
+		{"https://example.com/maps/Pages.XML.gz", true},
+		{"https://example.com/docs/", false},
+		{"https://example.com/feed.xml.html", false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if got := isSitemapURL(u); got != tt.want {
+			t.Errorf("isSitemapURL(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+// newSitemapSite serves a robots.txt pointing at a sitemap index, which
+// lists a plain and a gzipped sitemap, plus the default /sitemap.xml.
+func newSitemapSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	base := srv.URL
+
+	urlset := func(paths ...string) string {
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+		for _, p := range paths {
+			if strings.HasPrefix(p, "/") {
+				p = base + p
+			}
+			fmt.Fprintf(&b, "<url><loc> %s </loc></url>", p)
+		}
+		b.WriteString("</urlset>")
+		return b.String()
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(urlset("/blog/one", "/blog/two#comments")))
+	zw.Close()
+
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nAllow: /\nSitemap: %s/maps/index.xml\n", base)
+	})
+	mux.HandleFunc("/maps/index.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%[1]s/maps/docs.xml</loc></sitemap><sitemap><loc>%[1]s/maps/blog.xml.gz</loc></sitemap><sitemap><loc>%[1]s/maps/index.xml</loc></sitemap></sitemapindex>`, base)
+	})
+	mux.HandleFunc("/maps/docs.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, urlset("/docs/a", "/docs/b", "/docs/a"))
+	})
+	mux.HandleFunc("/maps/blog.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(gz.Bytes())
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, urlset("/about", "https://elsewhere.example/page"))
+	})
+	return srv
+}
+
+func TestExpandSitemaps(t *testing.T) {
+	srv := newSitemapSite(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	hostname, _, _ := strings.Cut(host, ":")
+	robots := newRobotsCache(http.DefaultTransport, 5*time.Second, "")
+	seed := srv.URL + "/"
+
+	tests := []struct {
+		name    string
+		allowed []string
+		keep    func(string) bool
+		limit   int
+		want    []string
+	}{
+		{
+			name: "everything",
+			want: []string{"/", "/docs/a", "/docs/b", "/blog/one", "/blog/two", "/about", "https://elsewhere.example/page"},
+		},
+		{
+			name:    "allowed hosts only",
+			allowed: []string{hostname},
+			want:    []string{"/", "/docs/a", "/docs/b", "/blog/one", "/blog/two", "/about"},
+		},
+		{
+			name:    "filtered",
+			allowed: []string{hostname},
+			keep:    func(u string) bool { return strings.Contains(u, "/blog/") },
+			want:    []string{"/", "/blog/one", "/blog/two"},
+		},
+		{
+			name:  "limited",
+			limit: 3,
+			want:  []string{"/", "/docs/a", "/docs/b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandSitemaps(context.Background(), robots, []string{seed}, tt.allowed, tt.keep, tt.limit)
+			var want []string
+			for _, p := range tt.want {
+				if strings.HasPrefix(p, "/") {
+					p = srv.URL + p
+				}
+				want = append(want, p)
+			}
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("expandSitemaps() =\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
+
+func TestExpandSitemapsFromSitemapSeed(t *testing.T) {
+	srv := newSitemapSite(t)
+	robots := newRobotsCache(http.DefaultTransport, 5*time.Second, "")
+	// A sitemap given as a seed is expanded, not scraped, and replaces
+	// discovery: neither robots.txt's sitemaps nor /sitemap.xml are read.
+	got := expandSitemaps(context.Background(), robots, []string{srv.URL + "/maps/docs.xml", srv.URL + "/"}, nil, nil, 0)
+	want := []string{srv.URL + "/", srv.URL + "/docs/a", srv.URL + "/docs/b"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("expandSitemaps() = %q, want %q", got, want)
+	}
+}
+
+func TestExpandSitemapsOutlastsRobotsTimeout(t *testing.T) {
+	// Large sitemaps take longer than robots.txt, so they get their own
+	// timeout.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprintf(w, `<urlset><url><loc>http://%s/slow</loc></url></urlset>`, r.Host)
+	}))
+	defer srv.Close()
+	robots := newRobotsCache(http.DefaultTransport, 50*time.Millisecond, "")
+	got := expandSitemaps(context.Background(), robots, []string{srv.URL + "/sitemap.xml"}, nil, nil, 0)
+	if want := srv.URL + "/slow"; len(got) != 1 || got[0] != want {
+		t.Errorf("expandSitemaps() = %q, want [%q]", got, want)
+	}
+}
@@ -0,0 +1,249 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+)
+
+// The state file is an append-only JSON journal: one "start" record holding
+// the crawl scope, then "queued" records as requests are issued and "done"
+// records as results are stored, or "alias" records when a page turns out to
+// duplicate one already stored. Replaying it yields the finished results
+// and the frontier (queued but not done) of an interrupted crawl. Failed
+// pages are left on the frontier, so resuming retries them.
+
+// stateScope records the options that define a crawl so --resume can
+// continue it without repeating them on the command line.
+type stateScope struct {
+	URLs         []string `json:"urls"`
+	Depth        int      `json:"depth"`
+	MaxPages     int      `json:"max_pages,omitempty"`
+	CrossDomains bool     `json:"cross_domains,omitempty"`
+	Sitemap      bool     `json:"sitemap,omitempty"`
+	Include      []string `json:"include,omitempty"`
+	Exclude      []string `json:"exclude,omitempty"`
+	PathPrefix   string   `json:"path_prefix,omitempty"`
+	Dedupe       string   `json:"dedupe,omitempty"`
+}
+
+func scopeOf(opts Options) stateScope {
+	return stateScope{
+		URLs:         opts.URLs,
+		Depth:        opts.Depth,
+		MaxPages:     opts.MaxPages,
+		CrossDomains: opts.CrossDomains,
+		Sitemap:      opts.Sitemap,
+		Include:      opts.Include,
+		Exclude:      opts.Exclude,
+		PathPrefix:   opts.PathPrefix,
+		Dedupe:       opts.Dedupe,
+	}
+}
+
+func (s stateScope) apply(opts *Options) {
+	opts.URLs = s.URLs
+	opts.Depth = s.Depth
+	opts.MaxPages = s.MaxPages
+	opts.CrossDomains = s.CrossDomains
+	opts.Sitemap = s.Sitemap
+	opts.Include = s.Include
+	opts.Exclude = s.Exclude
+	opts.PathPrefix = s.PathPrefix
+	opts.Dedupe = s.Dedupe
+}
+
+type stateRecord struct {
+	Op     string       `json:"op"` // "start", "queued", "done", "alias"
+	Scope  *stateScope  `json:"scope,omitempty"`
+	URL    string       `json:"url,omitempty"`
+	Depth  int          `json:"depth,omitempty"` // colly depth, seeds are 1
+	Of     string       `json:"of,omitempty"`    // for "alias": the stored result's URL
+	Result *stateResult `json:"result,omitempty"`
+}
+
+type stateResult struct {
+	URL      string            `json:"url"`
+	Markdown string            `json:"markdown,omitempty"`
+	Source   string            `json:"source,omitempty"`
+	Err      string            `json:"err,omitempty"`
+	Meta     Metadata          `json:"meta,omitzero"`
+	Assets   map[string]string `json:"assets,omitempty"`
+	Aliases  []string          `json:"aliases,omitempty"`
+}
+
+func toStateResult(r Result) *stateResult {
+	sr := &stateResult{URL: r.URL, Markdown: r.Markdown, Source: r.Source, Meta: r.Meta, Assets: r.Assets, Aliases: r.Aliases}
+	if r.Err != nil {
+		sr.Err = r.Err.Error()
+	}
+	return sr
+}
+
+func (sr *stateResult) result() Result {
+	r := Result{URL: sr.URL, Markdown: sr.Markdown, Source: sr.Source, Meta: sr.Meta, Assets: sr.Assets, Aliases: sr.Aliases}
+	if sr.Err != "" {
+		r.Err = errors.New(sr.Err)
+	}
+	return r
+}
+
+// pendingURL is a frontier entry: queued in a previous run, never finished.
+type pendingURL struct {
+	URL   string
+	Depth int
+}
+
+// crawlState is what a state file says about an interrupted crawl.
+type crawlState struct {
+	scope   stateScope
+	results []Result
+	done    map[string]bool
+	pending []pendingURL
+}
+
+// stateJournal appends crawl progress to a state file. It is safe for
+// concurrent use from colly callbacks.
+type stateJournal struct {
+	mu     sync.Mutex
+	f      *os.File
+	enc    *json.Encoder
+	err    error
+	closed bool
+}
+
+// createStateJournal starts a new state file, replacing any existing one.
+func createStateJournal(path string, scope stateScope) (*stateJournal, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state file: %w", err)
+	}
+	j := &stateJournal{f: f, enc: json.NewEncoder(f)}
+	j.write(stateRecord{Op: "start", Scope: &scope})
+	if j.err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to write state file: %w", j.err)
+	}
+	return j, nil
+}
+
+// resumeStateJournal replays an existing state file and reopens it for
+// appending. A record cut short by an interrupted write is discarded.
+func resumeStateJournal(path string) (*stateJournal, *crawlState, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+
+	state := &crawlState{done: make(map[string]bool)}
+	queued := make(map[string]int)
+	stored := make(map[string]int) // result URL → index in state.results
+	var order []string
+	started := false
+
+	dec := json.NewDecoder(f)
+	var good int64
+	for {
+		var rec stateRecord
+		if err := dec.Decode(&rec); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) && !started {
+				_ = f.Close()
+				return nil, nil, fmt.Errorf("invalid state file: %w", err)
+			}
+			break
+		}
+		good = dec.InputOffset()
+
+		switch rec.Op {
+		case "start":
+			if rec.Scope != nil {
+				state.scope = *rec.Scope
+				started = true
+			}
+		case "queued":
+			if _, ok := queued[rec.URL]; !ok {
+				order = append(order, rec.URL)
+			}
+			queued[rec.URL] = rec.Depth
+		case "done":
+			if rec.Result != nil && rec.Result.Err == "" && !state.done[rec.Result.URL] {
+				state.done[rec.Result.URL] = true
+				stored[rec.Result.URL] = len(state.results)
+				state.results = append(state.results, rec.Result.result())
+			}
+		case "alias":
+			state.done[rec.URL] = true
+			if i, ok := stored[rec.Of]; ok && !slices.Contains(state.results[i].Aliases, rec.URL) {
+				state.results[i].Aliases = append(state.results[i].Aliases, rec.URL)
+			}
+		}
+	}
+	if !started {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("invalid state file: missing start record")
+	}
+
+	for _, u := range order {
+		if !state.done[u] {
+			state.pending = append(state.pending, pendingURL{URL: u, Depth: queued[u]})
+		}
+	}
+
+	// Drop any partial trailing record so new records start on a clean line.
+	if err := f.Truncate(good); err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("failed to repair state file: %w", err)
+	}
+	if _, err := f.Seek(good, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("failed to repair state file: %w", err)
+	}
+	if good > 0 {
+		if _, err := f.WriteString("\n"); err != nil {
+			_ = f.Close()
+			return nil, nil, fmt.Errorf("failed to repair state file: %w", err)
+		}
+	}
+
+	return &stateJournal{f: f, enc: json.NewEncoder(f)}, state, nil
+}
+
+func (j *stateJournal) write(rec stateRecord) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err != nil || j.closed {
+		return
+	}
+	j.err = j.enc.Encode(rec)
+}
+
+func (j *stateJournal) queued(rawURL string, depth int) {
+	j.write(stateRecord{Op: "queued", URL: rawURL, Depth: depth})
+}
+
+func (j *stateJournal) done(r Result) {
+	j.write(stateRecord{Op: "done", Result: toStateResult(r)})
+}
+
+func (j *stateJournal) alias(rawURL, keptURL string) {
+	j.write(stateRecord{Op: "alias", URL: rawURL, Of: keptURL})
+}
+
+// Close closes the state file and reports the first write error, if any.
+// Closing twice is harmless.
+func (j *stateJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return j.err
+	}
+	j.closed = true
+	if err := j.f.Close(); err != nil && j.err == nil {
+		j.err = err
+	}
+	return j.err
+}
@@ -0,0 +1,98 @@
+package scraper
+
+import (
+	"cmp"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// ErrTLS marks a request that failed while setting up TLS: an untrusted or
+// invalid server certificate, or a handshake the server rejected. Result.Err
+// wraps it along with the underlying error.
+var ErrTLS = errors.New("TLS failure")
+
+// tlsConfig builds the client TLS settings from opts, or returns nil to
+// keep Go's defaults. CACert adds to the system roots rather than
+// replacing them. ClientKey may be left empty when ClientCert holds both
+// the certificate and its key.
+func tlsConfig(opts Options) (*tls.Config, error) {
+	if opts.CACert == "" && opts.ClientCert == "" && opts.ClientKey == "" && !opts.Insecure {
+		return nil, nil
+	}
+	cfg := &tls.Config{InsecureSkipVerify: opts.Insecure}
+
+	if opts.CACert != "" {
+		pem, err := os.ReadFile(opts.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", opts.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case opts.ClientCert != "":
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, cmp.Or(opts.ClientKey, opts.ClientCert))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case opts.ClientKey != "":
+		return nil, fmt.Errorf("a client key needs a client certificate")
+	}
+	return cfg, nil
+}
+
+// classifyTLS wraps err in ErrTLS, naming what went wrong, when it is a
+// TLS failure. Other errors are returned unchanged.
+func classifyTLS(err error) error {
+	if err == nil {
+		return nil
+	}
+	var (
+		unknownCA    x509.UnknownAuthorityError
+		hostname     x509.HostnameError
+		invalid      x509.CertificateInvalidError
+		verification *tls.CertificateVerificationError
+		remote       *net.OpError
+		record       tls.RecordHeaderError
+	)
+	var kind string
+	switch {
+	case errors.As(err, &unknownCA):
+		kind = "certificate signed by an unknown authority"
+	case errors.As(err, &hostname):
+		kind = "certificate is not valid for this host"
+	case errors.As(err, &invalid):
+		kind = "invalid certificate"
+	case errors.As(err, &verification):
+		kind = "certificate verification failed"
+	case errors.As(err, &remote) && remote.Op == "remote error":
+		// crypto/tls reports alerts from the server this way. These two
+		// come from servers that want a (different) client certificate.
+		if msg := remote.Err.Error(); strings.Contains(msg, "certificate required") || strings.Contains(msg, "bad certificate") {
+			kind = "server rejected the client certificate"
+		} else {
+			kind = "handshake failed"
+		}
+	case errors.As(err, &record),
+		// net/http replaces the RecordHeaderError with its own message
+		// when the reply looks like plain HTTP.
+		strings.Contains(err.Error(), "server gave HTTP response to HTTPS client"):
+		kind = "server did not answer with TLS"
+	default:
+		return err
+	}
+	return fmt.Errorf("%w (%s): %w", ErrTLS, kind, err)
+}
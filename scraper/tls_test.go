@@ -0,0 +1,151 @@
+package scraper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePEM writes blocks of the given type to a file in dir.
+func writePEM(t *testing.T, dir, name, typ string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newClientCert writes a self-signed client certificate and its key.
+func newClientCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, dir, "client.pem", "CERTIFICATE", der), writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDER)
+}
+
+func TestTLSFailures(t *testing.T) {
+	dir := t.TempDir()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// Failed handshakes are the point here; keep the servers quiet.
+	quiet := log.New(io.Discard, "", 0)
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Config.ErrorLog = quiet
+	srv.StartTLS()
+	defer srv.Close()
+	mtls := httptest.NewUnstartedServer(handler)
+	mtls.Config.ErrorLog = quiet
+	mtls.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	mtls.StartTLS()
+	defer mtls.Close()
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+
+	ca := writePEM(t, dir, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+	mtlsCA := writePEM(t, dir, "mtls-ca.pem", "CERTIFICATE", mtls.Certificate().Raw)
+	clientCert, clientKey := newClientCert(t, dir)
+	// The test certificate does not cover "localhost".
+	wrongHost := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		name string
+		opts Options
+		url  string
+		want string // "" = success
+	}{
+		{name: "untrusted", url: srv.URL, want: "certificate signed by an unknown authority"},
+		{name: "trusted with --ca-cert", opts: Options{CACert: ca}, url: srv.URL},
+		{name: "hostname mismatch", opts: Options{CACert: ca}, url: wrongHost, want: "certificate is not valid for this host"},
+		{name: "insecure", opts: Options{Insecure: true}, url: wrongHost},
+		{name: "client certificate required", opts: Options{CACert: mtlsCA}, url: mtls.URL, want: "server rejected the client certificate"},
+		{name: "client certificate", opts: Options{CACert: mtlsCA, ClientCert: clientCert, ClientKey: clientKey}, url: mtls.URL},
+		{name: "not TLS", url: strings.Replace(plain.URL, "http:", "https:", 1), want: "server did not answer with TLS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tlsConfig(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}, Timeout: 5 * time.Second}
+			resp, err := client.Get(tt.url)
+			if err == nil {
+				resp.Body.Close()
+			}
+			err = classifyTLS(err)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrTLS) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want ErrTLS (%s)", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyTLSLeavesOtherErrors(t *testing.T) {
+	for _, err := range []error{nil, errors.New("connection refused"), os.ErrDeadlineExceeded} {
+		if got := classifyTLS(err); got != err {
+			t.Errorf("classifyTLS(%v) = %v, want it unchanged", err, got)
+		}
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "not.pem")
+	if err := os.WriteFile(notPEM, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"missing CA file", Options{CACert: filepath.Join(dir, "missing.pem")}, "failed to read CA certificate"},
+		{"CA file without certificates", Options{CACert: notPEM}, "no PEM certificates found"},
+		{"key without certificate", Options{ClientKey: notPEM}, "needs a client certificate"},
+		{"bad client certificate", Options{ClientCert: notPEM}, "failed to load client certificate"},
+	}
+	for _, tt := range tests {
+		if _, err := tlsConfig(tt.opts); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+	if cfg, err := tlsConfig(Options{}); cfg != nil || err != nil {
+		t.Errorf("tlsConfig(no TLS options) = %v, %v; want nil, nil", cfg, err)
+	}
+}
@@ -0,0 +1,47 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// buildTransport assembles the HTTP transport shared by the collector and
+// the robots.txt and sitemap fetchers, so every request honors the same
+// cache settings, goes through the same proxies, trusts the same
+// certificates and carries the same headers and cookies. Network round
+// trips are reported to limiter, if set.
+func buildTransport(opts Options, limiter *hostLimiter, sess *session) (http.RoundTripper, error) {
+	proxies, err := newProxyRouter(opts.Proxies)
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg, err := tlsConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = proxies.proxy
+	if tlsCfg != nil {
+		base.TLSClientConfig = tlsCfg
+	}
+
+	var rt http.RoundTripper = base
+	if limiter != nil {
+		rt = &observedTransport{next: rt, limiter: limiter}
+	}
+	if opts.Offline && opts.CacheDir == "" {
+		return nil, fmt.Errorf("offline mode requires a cache directory")
+	}
+	if opts.CacheDir != "" {
+		ct, err := newCachingTransport(opts.CacheDir, opts.Offline, sess.headerNames(), rt)
+		if err != nil {
+			return nil, err
+		}
+		rt = ct
+	}
+	// Above the cache, so it can tell which requests carry credentials.
+	if sess != nil {
+		rt = &sessionTransport{next: rt, session: sess}
+	}
+	return rt, nil
+}
@@ -317,7 +317,9 @@ func (m *browserModel) rebuildFilter() {
 	query := strings.ToLower(m.filterInput.Value())
 	m.filtered = m.filtered[:0]
 	for i, r := range m.results {
-		if query == "" || strings.Contains(strings.ToLower(r.URL), query) {
+		if query == "" ||
+			strings.Contains(strings.ToLower(r.URL), query) ||
+			strings.Contains(strings.ToLower(r.Meta.Title), query) {
 			m.filtered = append(m.filtered, i)
 		}
 	}
@@ -672,13 +674,18 @@ func (m browserModel) listView() string {
 		switch {
 		case r.Err != nil:
 			badge = errBadgeStyle.Render("✗")
-		case r.Source == "native":
+		case r.Source == "native", r.Source == "sibling":
 			badge = nativeBadgeStyle.Render("●")
 		default:
 			badge = convertedBadgeStyle.Render("●")
 		}
 
-		urlStr := truncateURL(r.URL, truncTo)
+		// Title line shows the page title when known, the URL otherwise.
+		title := r.URL
+		if r.Meta.Title != "" {
+			title = r.Meta.Title
+		}
+		title = truncateURL(title, truncTo)
 
 		// Meta info
 		var meta string
@@ -689,11 +696,15 @@ func (m browserModel) listView() string {
 			}
 			meta = metStyle.Render(errMsg)
 		} else {
-			meta = metStyle.Render(r.Source + " markdown")
+			info := r.Source + " markdown"
+			if r.Meta.Title != "" {
+				info = r.URL + "  •  " + info
+			}
+			meta = metStyle.Render(truncateURL(info, truncTo))
 		}
 
 		b.WriteString("\n")
-		fmt.Fprintf(&b, "  %s  %s  %s\n", gut, badge, titStyle.Render(urlStr))
+		fmt.Fprintf(&b, "  %s  %s  %s\n", gut, badge, titStyle.Render(title))
 		fmt.Fprintf(&b, "       %s\n", meta)
 	}
 
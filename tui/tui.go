@@ -9,6 +9,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"charm.land/bubbles/v2/progress"
@@ -64,6 +65,7 @@ type model struct {
 	completed  int
 	activeURLs []string
 	activeSet  map[string]bool
+	filtered   []string
 	results    []scraper.Result
 	err        error
 	done       bool
@@ -206,13 +208,20 @@ func (m model) handleScrapeEvent(e scraper.Event) (tea.Model, tea.Cmd) {
 			break
 		}
 		m.completed++
-		tag := green.Render("native")
+		tag := green.Render(e.Source)
 		if e.Source == "converted" {
 			tag = yellow.Render("converted")
 		}
 		entry := fmt.Sprintf("  %s %s [%s]", green.Render("✓"), truncateURL(e.URL, truncW), tag)
 		m.logEntries = append(m.logEntries, entry)
 
+	case "duplicate":
+		m.completed++
+		m.removeActive(e.URL)
+		entry := fmt.Sprintf("  %s %s %s", subtle.Render("≡"), truncateURL(e.URL, max(20, truncW-40)),
+			subtle.Render("duplicate of "+truncateURL(e.Of, 30)))
+		m.logEntries = append(m.logEntries, entry)
+
 	case "error":
 		m.completed++
 		m.removeActive(e.URL)
@@ -225,6 +234,26 @@ func (m model) handleScrapeEvent(e scraper.Event) (tea.Model, tea.Cmd) {
 		}
 		entry := fmt.Sprintf("  %s %s %s", red.Render("✗"), truncateURL(e.URL, max(20, truncW-50)), subtle.Render(errMsg))
 		m.logEntries = append(m.logEntries, entry)
+
+	case "blocked":
+		// Never fetched, so it does not count towards progress.
+		entry := fmt.Sprintf("  %s %s [%s]", subtle.Render("⊘"), truncateURL(e.URL, truncW), subtle.Render("robots.txt"))
+		m.logEntries = append(m.logEntries, entry)
+
+	case "retrying":
+		// The URL stays active; the request is re-queued after the delay.
+		entry := fmt.Sprintf("  %s %s %s", yellow.Render("↻"), truncateURL(e.URL, max(20, truncW-30)),
+			subtle.Render(fmt.Sprintf("retry %d in %s", e.Attempt, e.Delay.Round(100*time.Millisecond))))
+		m.logEntries = append(m.logEntries, entry)
+
+	case "throttled":
+		entry := fmt.Sprintf("  %s %s %s", yellow.Render("⏸"), truncateURL(e.URL, max(20, truncW-30)),
+			subtle.Render(fmt.Sprintf("slowing down: %s between requests", e.Delay.Round(10*time.Millisecond))))
+		m.logEntries = append(m.logEntries, entry)
+
+	case "filtered":
+		// Too noisy for the log; summarized after the run instead.
+		m.filtered = append(m.filtered, e.URL)
 	}
 
 	var pct float64
@@ -271,6 +300,9 @@ func (m model) View() tea.View {
 			progLine += subtle.Render(fmt.Sprintf(" (%d active)", len(m.activeURLs)))
 		}
 	}
+	if len(m.filtered) > 0 {
+		progLine += subtle.Render(fmt.Sprintf(" • %d filtered", len(m.filtered)))
+	}
 	lines = append(lines, progLine)
 	lines = append(lines, "")
 
@@ -359,7 +391,11 @@ func RunWithProgress(ctx context.Context, opts scraper.Options, noTUI bool) ([]s
 	}
 
 	go func() {
+		next := opts.OnEvent
 		opts.OnEvent = func(e scraper.Event) {
+			if next != nil {
+				next(e)
+			}
 			prog.Send(scrapeEventMsg(e))
 		}
 		results, err := scraper.Run(ctx, opts)
@@ -380,6 +416,7 @@ func RunWithProgress(ctx context.Context, opts scraper.Options, noTUI bool) ([]s
 	}
 
 	fm := finalModel.(model)
+	printFilteredSummary(fm.filtered)
 	return fm.results, fm.err
 }
 
@@ -387,30 +424,88 @@ func runWithLogs(ctx context.Context, opts scraper.Options) ([]scraper.Result, e
 	logger := log.New(os.Stderr)
 	logger.SetLevel(log.InfoLevel)
 
-	logger.Info("Starting scrape", "urls", len(opts.URLs), "depth", opts.Depth, "parallelism", opts.Parallelism)
+	if opts.Resume {
+		logger.Info("Resuming scrape", "state", opts.StateFile, "parallelism", opts.Parallelism)
+	} else {
+		logger.Info("Starting scrape", "urls", len(opts.URLs), "depth", opts.Depth, "parallelism", opts.Parallelism)
+	}
 
+	// OnEvent is called from colly's worker goroutines.
+	var (
+		mu       sync.Mutex
+		filtered []string
+	)
+	next := opts.OnEvent
 	opts.OnEvent = func(e scraper.Event) {
+		if next != nil {
+			next(e)
+		}
 		switch e.Type {
+		case "filtered":
+			mu.Lock()
+			filtered = append(filtered, e.URL)
+			mu.Unlock()
 		case "fetching":
 			logger.Info("Fetching", "url", e.URL)
 		case "done":
 			if e.Source != "skipped" {
 				logger.Info("Done", "url", e.URL, "source", e.Source)
 			}
+		case "duplicate":
+			logger.Info("Duplicate", "url", e.URL, "of", e.Of)
 		case "error":
 			logger.Error("Failed", "url", e.URL, "err", e.Err)
+		case "blocked":
+			logger.Warn("Blocked by robots.txt", "url", e.URL)
+		case "retrying":
+			logger.Warn("Retrying", "url", e.URL, "attempt", e.Attempt, "in", e.Delay.Round(100*time.Millisecond), "err", e.Err)
+		case "throttled":
+			logger.Warn("Slowing down", "url", e.URL, "delay", e.Delay.Round(10*time.Millisecond))
+		}
+	}
+
+	// Streamed results are not returned, so count them on the way past.
+	var streamed int
+	if next := opts.OnResult; next != nil {
+		opts.OnResult = func(r scraper.Result) {
+			streamed++
+			next(r)
 		}
 	}
 
 	results, err := scraper.Run(ctx, opts)
 	if err != nil {
-		return nil, err
+		// Run can fail after pages were scraped; callers may still want them.
+		return results, err
 	}
 
-	logger.Info("Scraping complete", "total", len(results))
+	logger.Info("Scraping complete", "total", len(results)+streamed, "filtered", len(filtered))
+	printFilteredSummary(filtered)
 	return results, nil
 }
 
+// maxFilteredShown caps how many filtered URLs the summary lists.
+const maxFilteredShown = 10
+
+// printFilteredSummary lists URLs skipped by --include, --exclude and
+// --path-prefix on stderr so users can tell whether the scope is too tight.
+func printFilteredSummary(urls []string) {
+	if len(urls) == 0 {
+		return
+	}
+	sorted := make([]string, len(urls))
+	copy(sorted, urls)
+	sort.Strings(sorted)
+
+	fmt.Fprintf(os.Stderr, "Filtered %d URLs outside the crawl scope:\n", len(sorted))
+	for _, u := range sorted[:min(len(sorted), maxFilteredShown)] {
+		fmt.Fprintf(os.Stderr, "  %s\n", u)
+	}
+	if len(sorted) > maxFilteredShown {
+		fmt.Fprintf(os.Stderr, "  ...and %d more\n", len(sorted)-maxFilteredShown)
+	}
+}
+
 func truncateURL(u string, maxLen int) string {
 	if len(u) <= maxLen {
 		return u